@@ -47,19 +47,11 @@ func (atcConfig ATCConfig) newConfig(configPath PathFlag, templateVariablesFiles
 		failWithErrorf("could not read config file", err)
 	}
 
-	var resultVars template.Variables
-
-	for _, path := range templateVariablesFiles {
-		fileVars, templateErr := template.LoadVariablesFromFile(string(path))
-		if templateErr != nil {
-			failWithErrorf("failed to load variables from file (%s)", templateErr, string(path))
-		}
-
-		resultVars = resultVars.Merge(fileVars)
+	resultVars, err := loadTemplateVariables(templateVariablesFiles, templateVariables)
+	if err != nil {
+		failf("%s", err)
 	}
 
-	resultVars = resultVars.Merge(templateVariables)
-
 	configFile, err = template.Evaluate(configFile, resultVars)
 	if err != nil {
 		failWithErrorf("failed to evaluate variables into template", err)
@@ -74,6 +66,21 @@ func (atcConfig ATCConfig) newConfig(configPath PathFlag, templateVariablesFiles
 	return newConfig
 }
 
+func loadTemplateVariables(templateVariablesFiles []PathFlag, templateVariables template.Variables) (template.Variables, error) {
+	var resultVars template.Variables
+
+	for _, path := range templateVariablesFiles {
+		fileVars, err := template.LoadVariablesFromFile(string(path))
+		if err != nil {
+			return nil, fmt.Errorf("failed to load variables from file (%s): %s", string(path), err)
+		}
+
+		resultVars = resultVars.Merge(fileVars)
+	}
+
+	return resultVars.Merge(templateVariables), nil
+}
+
 func (atcConfig ATCConfig) showHelpfulMessage(created bool, updated bool) {
 	if updated {
 		fmt.Println("configuration updated")
@@ -97,7 +104,7 @@ func (atcConfig ATCConfig) showHelpfulMessage(created bool, updated bool) {
 		fmt.Println("  - run the unpause-pipeline command")
 		fmt.Println("  - click play next to the pipeline in the web ui")
 	} else {
-		panic("Something really went wrong!")
+		failf("the pipeline was neither created nor updated")
 	}
 }
 
@@ -135,6 +142,6 @@ func diff(existingConfig atc.Config, newConfig atc.Config) {
 	err := interact.NewInteraction("apply configuration?").Resolve(&confirm)
 	if err != nil || !confirm {
 		fmt.Println("bailing out")
-		os.Exit(1)
+		os.Exit(ExitAborted)
 	}
 }
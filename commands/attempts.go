@@ -0,0 +1,56 @@
+package commands
+
+import (
+	"io"
+	"strconv"
+
+	"github.com/concourse/atc"
+	"github.com/concourse/fly/ui"
+	"github.com/fatih/color"
+)
+
+type buildAttempt struct {
+	Build  atc.Build
+	Status atc.BuildStatus
+}
+
+// retryableStatus determines whether a build that finished with the given
+// status should be run again. Aborted builds were stopped on purpose, so they
+// are never retried.
+func retryableStatus(retryOn string, status atc.BuildStatus) bool {
+	switch retryOn {
+	case "errored":
+		return status == atc.StatusErrored
+	case "failed":
+		return status == atc.StatusFailed
+	case "any":
+		return status == atc.StatusErrored || status == atc.StatusFailed
+	default:
+		return false
+	}
+}
+
+func printAttempts(dst io.Writer, attempts []buildAttempt) error {
+	table := ui.Table{
+		Headers: ui.TableRow{
+			{Contents: "attempt", Color: color.New(color.Bold)},
+			{Contents: "build", Color: color.New(color.Bold)},
+			{Contents: "status", Color: color.New(color.Bold)},
+		},
+	}
+
+	for i, attempt := range attempts {
+		status := string(attempt.Status)
+		if status == "" {
+			status = "unknown"
+		}
+
+		table.Data = append(table.Data, ui.TableRow{
+			{Contents: strconv.Itoa(i + 1)},
+			{Contents: strconv.Itoa(attempt.Build.ID)},
+			{Contents: status},
+		})
+	}
+
+	return table.Render(dst)
+}
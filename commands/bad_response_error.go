@@ -5,6 +5,24 @@ import (
 	"net/http"
 )
 
+type badResponse struct {
+	doing      string
+	status     string
+	statusCode int
+}
+
+func (err badResponse) Error() string {
+	return fmt.Sprintf("bad response %s (%s)", err.doing, err.status)
+}
+
 func badResponseError(doing string, response *http.Response) error {
-	return fmt.Errorf("bad response %s (%s)", doing, response.Status)
+	return badResponse{
+		doing:      doing,
+		status:     response.Status,
+		statusCode: response.StatusCode,
+	}
+}
+
+func isAuthStatus(statusCode int) bool {
+	return statusCode == http.StatusUnauthorized || statusCode == http.StatusForbidden
 }
@@ -0,0 +1,100 @@
+package commands
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"regexp"
+	"sync"
+
+	"github.com/concourse/atc"
+	"github.com/concourse/atc/event"
+	"github.com/concourse/go-concourse/concourse"
+)
+
+// buildLog is the file that a copy of a build's output is saved to. It is
+// written unbuffered, so that everything up to an interrupt ends up in it.
+type buildLog struct {
+	lock sync.Mutex
+	file *os.File
+}
+
+func createBuildLog(path string) (*buildLog, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &buildLog{file: file}, nil
+}
+
+func (log *buildLog) Write(p []byte) (int, error) {
+	log.lock.Lock()
+	defer log.lock.Unlock()
+
+	return log.file.Write(p)
+}
+
+func (log *buildLog) Close() error {
+	log.lock.Lock()
+	defer log.lock.Unlock()
+
+	return log.file.Close()
+}
+
+// loggedEvents writes each event to the log as a line of JSON as it is read.
+type loggedEvents struct {
+	concourse.Events
+
+	log *buildLog
+}
+
+func (events *loggedEvents) NextEvent() (atc.Event, error) {
+	e, err := events.Events.NextEvent()
+	if err != nil {
+		return e, err
+	}
+
+	payload, err := json.Marshal(event.Message{Event: e})
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = events.log.Write(append(payload, '\n'))
+	if err != nil {
+		return nil, err
+	}
+
+	return e, nil
+}
+
+var ansiEscapeRegex = regexp.MustCompile("\x1b\\[[0-9;?]*[ -/]*[@-~]")
+
+// an escape sequence that has been started but not finished
+var partialAnsiEscapeRegex = regexp.MustCompile("\x1b(\\[[0-9;?]*[ -/]*)?$")
+
+// ansiStrippingWriter removes ANSI escape sequences, such as colors, from
+// what is written through it. A sequence split across writes is held back
+// until it is complete.
+type ansiStrippingWriter struct {
+	dst io.Writer
+
+	partial []byte
+}
+
+func (writer *ansiStrippingWriter) Write(p []byte) (int, error) {
+	buf := append(writer.partial, p...)
+
+	writer.partial = nil
+	if loc := partialAnsiEscapeRegex.FindIndex(buf); loc != nil {
+		writer.partial = append([]byte{}, buf[loc[0]:]...)
+		buf = buf[:loc[0]]
+	}
+
+	_, err := writer.dst.Write(ansiEscapeRegex.ReplaceAll(buf, nil))
+	if err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
@@ -0,0 +1,177 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/concourse/atc"
+	"github.com/concourse/atc/event"
+	"github.com/concourse/fly/rc"
+	"github.com/concourse/go-concourse/concourse"
+	"github.com/concourse/go-concourse/concourse/eventstream"
+	"github.com/fatih/color"
+)
+
+type BuildLogsCommand struct {
+	Job     JobFlag `short:"j" long:"job"      value-name:"PIPELINE/JOB" description:"The job that the build belongs to"`
+	Build   string  `short:"b" long:"build"                              required:"true" description:"The build to download the output of, by ID or by name within --job"`
+	Format  string  `long:"format"                                       default:"plain" choice:"plain" choice:"ansi" choice:"json" description:"Write the output as plain text, text with colors, or JSON with one event per line"`
+	Output  string  `short:"o" long:"output"   value-name:"PATH"         description:"Write the output to a file instead of stdout"`
+	StepDir string  `long:"step-dir"           value-name:"DIR"          description:"Also write the output of each step to its own file in the given directory"`
+}
+
+func (command *BuildLogsCommand) Execute(args []string) error {
+	connection, err := rc.TargetConnection(Fly.Target)
+	if err != nil {
+		fail(err)
+		return nil
+	}
+
+	client := concourse.NewClient(connection)
+
+	build, err := GetBuild(client, command.Job.JobName, command.Build, command.Job.PipelineName)
+	if err != nil {
+		fail(err)
+	}
+
+	// a running build's events would not end until it does
+	if build.Status == string(atc.StatusPending) || build.Status == string(atc.StatusStarted) {
+		return fmt.Errorf("build %d has not finished yet (stream its output with: fly -t %s watch -b %d)", build.ID, Fly.Target, build.ID)
+	}
+
+	events, err := readBuildEvents(client, build)
+	if err != nil {
+		failWithErrorf("failed to read the build's events", err)
+	}
+
+	var dst io.Writer = os.Stdout
+	if command.Output != "" {
+		file, err := os.Create(command.Output)
+		if err != nil {
+			return err
+		}
+
+		defer file.Close()
+
+		dst = file
+	}
+
+	err = command.write(dst, events)
+	if err != nil {
+		return err
+	}
+
+	if command.StepDir != "" {
+		err = command.writeSteps(events)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func readBuildEvents(client concourse.Client, build atc.Build) ([]atc.Event, error) {
+	source, err := buildEvents(client, build)
+	if err != nil {
+		return nil, err
+	}
+
+	defer source.Close()
+
+	events := []atc.Event{}
+	for {
+		e, err := source.NextEvent()
+		if err == io.EOF {
+			return events, nil
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		events = append(events, e)
+	}
+}
+
+func (command *BuildLogsCommand) write(dst io.Writer, events []atc.Event) error {
+	source := &eventList{events: events}
+
+	switch command.Format {
+	case "json":
+		if renderJSON(dst, source) != ExitSucceeded {
+			return errors.New("failed to write the build's events")
+		}
+
+		return nil
+
+	case "plain":
+		dst = &ansiStrippingWriter{dst: dst}
+
+	case "ansi":
+		// keep the colors even though the output is a file or a pipe
+		color.NoColor = false
+	}
+
+	eventstream.Render(dst, source)
+
+	return nil
+}
+
+// writeSteps writes the events of each step to a file named after its type
+// and name, e.g. task-unit.log.
+func (command *BuildLogsCommand) writeSteps(events []atc.Event) error {
+	err := os.MkdirAll(command.StepDir, 0755)
+	if err != nil {
+		return err
+	}
+
+	extension := ".log"
+	if command.Format == "json" {
+		extension = ".json"
+	}
+
+	steps := []string{}
+	stepEvents := map[string][]atc.Event{}
+
+	for _, e := range events {
+		origin, found := eventOrigin(e)
+		if !found || origin.Name == "" {
+			continue
+		}
+
+		step := stepFileName(origin)
+		if _, seen := stepEvents[step]; !seen {
+			steps = append(steps, step)
+		}
+
+		stepEvents[step] = append(stepEvents[step], e)
+	}
+
+	for _, step := range steps {
+		file, err := os.Create(filepath.Join(command.StepDir, step+extension))
+		if err != nil {
+			return err
+		}
+
+		err = command.write(file, stepEvents[step])
+		file.Close()
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func stepFileName(origin event.Origin) string {
+	name := strings.Replace(origin.Name, "/", "_", -1)
+	name = strings.Replace(name, string(filepath.Separator), "_", -1)
+
+	return fmt.Sprintf("%s-%s", origin.Type, name)
+}
@@ -0,0 +1,65 @@
+package commands
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type ByteSizeFlag int64
+
+var byteSizeUnits = []struct {
+	suffix string
+	size   int64
+}{
+	{"TB", 1 << 40},
+	{"GB", 1 << 30},
+	{"MB", 1 << 20},
+	{"KB", 1 << 10},
+	{"T", 1 << 40},
+	{"G", 1 << 30},
+	{"M", 1 << 20},
+	{"K", 1 << 10},
+	{"B", 1},
+}
+
+func (size *ByteSizeFlag) UnmarshalFlag(value string) error {
+	bytes, err := parseByteSize(value)
+	if err != nil {
+		return err
+	}
+
+	*size = ByteSizeFlag(bytes)
+
+	return nil
+}
+
+func parseByteSize(value string) (int64, error) {
+	number := strings.ToUpper(strings.TrimSpace(value))
+	multiplier := int64(1)
+
+	for _, unit := range byteSizeUnits {
+		if strings.HasSuffix(number, unit.suffix) {
+			number = strings.TrimSpace(strings.TrimSuffix(number, unit.suffix))
+			multiplier = unit.size
+			break
+		}
+	}
+
+	n, err := strconv.ParseFloat(number, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size '%s' (must be e.g. 500MB or 2G)", value)
+	}
+
+	return int64(n * float64(multiplier)), nil
+}
+
+func formatByteSize(bytes int64) string {
+	for _, unit := range byteSizeUnits {
+		if bytes >= unit.size && unit.size > 1 {
+			return strconv.FormatFloat(float64(bytes)/float64(unit.size), 'f', 1, 64) + unit.suffix
+		}
+	}
+
+	return strconv.FormatInt(bytes, 10) + "B"
+}
@@ -2,7 +2,6 @@ package commands
 
 import (
 	"fmt"
-	"log"
 
 	"github.com/concourse/atc"
 	"github.com/concourse/fly/rc"
@@ -16,7 +15,7 @@ type ChecklistCommand struct {
 func (command *ChecklistCommand) Execute([]string) error {
 	connection, err := rc.TargetConnection(Fly.Target)
 	if err != nil {
-		log.Fatalln(err)
+		fail(err)
 	}
 
 	pipelineName := command.Pipeline
@@ -24,7 +23,7 @@ func (command *ChecklistCommand) Execute([]string) error {
 	client := concourse.NewClient(connection)
 	config, _, _, err := client.PipelineConfig(pipelineName)
 	if err != nil {
-		log.Fatalln(err)
+		fail(err)
 	}
 
 	printCheckfile(pipelineName, config, connection.URL())
@@ -1,7 +1,6 @@
 package commands
 
 import (
-	"log"
 	"os"
 	"sort"
 	"strconv"
@@ -18,14 +17,14 @@ type ContainersCommand struct{}
 func (command *ContainersCommand) Execute([]string) error {
 	connection, err := rc.TargetConnection(Fly.Target)
 	if err != nil {
-		log.Fatalln(err)
+		fail(err)
 	}
 
 	client := concourse.NewClient(connection)
 
 	containers, err := client.ListContainers(map[string]string{})
 	if err != nil {
-		log.Fatalln(err)
+		fail(err)
 	}
 
 	table := ui.Table{
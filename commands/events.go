@@ -0,0 +1,295 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/concourse/atc"
+	"github.com/concourse/atc/event"
+	"github.com/concourse/go-concourse/concourse"
+	"github.com/fatih/color"
+)
+
+func eventOrigin(e atc.Event) (event.Origin, bool) {
+	switch ev := e.(type) {
+	case event.Log:
+		return ev.Origin, true
+	case event.Error:
+		return ev.Origin, true
+	case event.InitializeTask:
+		return ev.Origin, true
+	case event.StartTask:
+		return ev.Origin, true
+	case event.FinishTask:
+		return ev.Origin, true
+	case event.FinishGet:
+		return ev.Origin, true
+	case event.FinishPut:
+		return ev.Origin, true
+	default:
+		return event.Origin{}, false
+	}
+}
+
+// stepHeaderEvents prints the name of each task step before its output, so
+// that multi-step builds can be told apart.
+type stepHeaderEvents struct {
+	concourse.Events
+
+	dst      io.Writer
+	lastStep string
+}
+
+func (events *stepHeaderEvents) NextEvent() (atc.Event, error) {
+	e, err := events.Events.NextEvent()
+	if err != nil {
+		return e, err
+	}
+
+	origin, found := eventOrigin(e)
+	if found && origin.Type == event.OriginTypeTask && origin.Name != events.lastStep {
+		events.lastStep = origin.Name
+		fmt.Fprintf(events.dst, "%s\n", color.New(color.Bold).SprintFunc()(origin.Name))
+	}
+
+	return e, nil
+}
+
+// filteredEvents leaves out the events of steps that are not to be shown.
+// Events that do not belong to a step, such as the build's status, are always
+// kept.
+type filteredEvents struct {
+	concourse.Events
+
+	steps         []string
+	hideResources bool
+	statusOnly    bool
+
+	// where to summarize hidden resources, if at all
+	dst io.Writer
+}
+
+func (events *filteredEvents) NextEvent() (atc.Event, error) {
+	for {
+		e, err := events.Events.NextEvent()
+		if err != nil {
+			return e, err
+		}
+
+		if events.keep(e) {
+			return e, nil
+		}
+	}
+}
+
+func (events *filteredEvents) keep(e atc.Event) bool {
+	if events.statusOnly {
+		_, isStatus := e.(event.Status)
+		return isStatus
+	}
+
+	origin, found := eventOrigin(e)
+	if !found {
+		return true
+	}
+
+	if len(events.steps) > 0 && !containsString(events.steps, origin.Name) {
+		return false
+	}
+
+	if events.hideResources && (origin.Type == event.OriginTypeGet || origin.Type == event.OriginTypePut) {
+		if events.dst != nil {
+			switch ev := e.(type) {
+			case event.FinishGet:
+				events.printResource(origin, exitStatusResult(ev.ExitStatus))
+			case event.FinishPut:
+				events.printResource(origin, exitStatusResult(ev.ExitStatus))
+			case event.Error:
+				events.printResource(origin, "errored: "+ev.Message)
+			}
+		}
+
+		return false
+	}
+
+	return true
+}
+
+func (events *filteredEvents) printResource(origin event.Origin, result string) {
+	resultColor := color.New(color.FgRed)
+	if result == "succeeded" {
+		resultColor = color.New(color.FgGreen)
+	}
+
+	fmt.Fprintf(events.dst, "%s %s\n", color.New(color.Bold).Sprintf("%s: %s", origin.Type, origin.Name), resultColor.Sprint(result))
+}
+
+func containsString(list []string, str string) bool {
+	for _, s := range list {
+		if s == str {
+			return true
+		}
+	}
+
+	return false
+}
+
+// statusEvents records the final status of the build as its events are read.
+type statusEvents struct {
+	concourse.Events
+
+	status atc.BuildStatus
+}
+
+func (events *statusEvents) NextEvent() (atc.Event, error) {
+	e, err := events.Events.NextEvent()
+	if err != nil {
+		return e, err
+	}
+
+	if status, ok := e.(event.Status); ok {
+		events.status = status.Status
+	}
+
+	return e, nil
+}
+
+// eventList plays back events that have already been read.
+type eventList struct {
+	events []atc.Event
+}
+
+func (list *eventList) NextEvent() (atc.Event, error) {
+	if len(list.events) == 0 {
+		return nil, io.EOF
+	}
+
+	e := list.events[0]
+	list.events = list.events[1:]
+
+	return e, nil
+}
+
+func (list *eventList) Close() error {
+	return nil
+}
+
+// how long to wait before each attempt to reattach to a build's events, and
+// how many attempts to make before giving up
+const (
+	reconnectInitialDelay = time.Second
+	reconnectMaxDelay     = 30 * time.Second
+	reconnectAttempts     = 10
+)
+
+// reconnectingEvents reattaches to a build's events if the connection drops
+// before the build has finished, e.g. when a laptop sleeps or a load balancer
+// times out an idle connection. The stream only ends once the build's final
+// status has been read; a stream that closes before then, cleanly or not, is
+// taken to mean the connection was lost. Reconnection gives up after several
+// attempts in a row that bring no new events.
+//
+// The ATC replays a build's events from the start on every connection, so the
+// events that have already been read are skipped.
+type reconnectingEvents struct {
+	concourse.Events
+
+	client  concourse.Client
+	buildID string
+	notices io.Writer
+
+	seen     int
+	skip     int
+	failures int
+	finished bool
+}
+
+func buildEvents(client concourse.Client, build atc.Build) (*reconnectingEvents, error) {
+	buildID := fmt.Sprintf("%d", build.ID)
+
+	eventSource, err := client.BuildEvents(buildID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &reconnectingEvents{
+		Events:  eventSource,
+		client:  client,
+		buildID: buildID,
+		notices: os.Stderr,
+	}, nil
+}
+
+func (events *reconnectingEvents) NextEvent() (atc.Event, error) {
+	for {
+		e, err := events.Events.NextEvent()
+		if err == io.EOF && events.finished {
+			return nil, err
+		}
+
+		if err == io.EOF {
+			err = errors.New("the stream ended before the build finished")
+		}
+
+		if err != nil {
+			err = events.reconnect(err)
+			if err != nil {
+				return nil, err
+			}
+
+			continue
+		}
+
+		if events.skip > 0 {
+			events.skip--
+			continue
+		}
+
+		events.seen++
+		events.failures = 0
+
+		if status, ok := e.(event.Status); ok {
+			events.finished = status.Status != atc.StatusStarted && status.Status != atc.StatusPending
+		}
+
+		return e, nil
+	}
+}
+
+func (events *reconnectingEvents) reconnect(cause error) error {
+	events.Events.Close()
+
+	for events.failures < reconnectAttempts {
+		delay := reconnectInitialDelay << uint(events.failures)
+		if delay > reconnectMaxDelay {
+			delay = reconnectMaxDelay
+		}
+
+		events.failures++
+
+		fmt.Fprintf(events.notices, "lost connection to the events of build %s (%s); reconnecting in %s...\n", events.buildID, cause, delay)
+
+		time.Sleep(delay)
+
+		eventSource, err := events.client.BuildEvents(events.buildID)
+		if err == nil {
+			fmt.Fprintf(events.notices, "reconnected to the events of build %s\n", events.buildID)
+
+			events.Events = eventSource
+			events.skip = events.seen
+
+			return nil
+		}
+
+		if isAuthError(err) {
+			return err
+		}
+
+		cause = err
+	}
+
+	return fmt.Errorf("gave up reconnecting to the events of build %s after %d attempts: %s", events.buildID, reconnectAttempts, cause)
+}
@@ -3,9 +3,11 @@ package commands
 import (
 	"bufio"
 	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
-	"log"
+	"io"
+	"io/ioutil"
 	"net/http"
 	"os"
 	"os/exec"
@@ -13,84 +15,369 @@ import (
 	"path/filepath"
 	"strconv"
 	"syscall"
+	"time"
 
 	"github.com/concourse/atc"
 	"github.com/concourse/fly/config"
 	"github.com/concourse/fly/rc"
+	"github.com/concourse/fly/template"
 	"github.com/concourse/go-concourse/concourse"
-	"github.com/concourse/go-concourse/concourse/eventstream"
+	"github.com/fatih/color"
 	"github.com/tedsuo/rata"
 )
 
 type ExecuteCommand struct {
-	TaskConfig     PathFlag         `short:"c" long:"config" required:"true"                description:"The task config to execute"`
-	Privileged     bool             `short:"p" long:"privileged"                            description:"Run the task with full privileges"`
-	ExcludeIgnored bool             `short:"x" long:"exclude-ignored"                       description:"Skip uploading .gitignored paths"`
-	Inputs         []InputPairFlag  `short:"i" long:"input"       value-name:"NAME=PATH"    description:"An input to provide to the task (can be specified multiple times)"`
-	InputsFrom     JobFlag          `short:"j" long:"inputs-from" value-name:"PIPELINE/JOB" description:"A job to base the inputs on"`
-	Outputs        []OutputPairFlag `short:"o" long:"output"      value-name:"NAME=PATH"    description:"An output to fetch from the task (can be specified multiple times)"`
+	TaskConfig     PathFlag           `short:"c" long:"config"                                         description:"The task config to execute"`
+	JobTask        JobTaskFlag        `long:"job-task"                  value-name:"PIPELINE/JOB/TASK" description:"A task in a pipeline's job to execute instead of a task config"`
+	Plan           PathFlag           `long:"plan"                                                     description:"A plan file describing several task steps to execute instead of a task config"`
+	Privileged     bool               `short:"p" long:"privileged"                                     description:"Run the task with full privileges"`
+	ExcludeIgnored bool               `short:"x" long:"exclude-ignored"                                description:"Skip uploading .gitignored paths"`
+	Inputs         []InputPairFlag    `short:"i" long:"input"           value-name:"NAME=PATH"         description:"An input to provide to the task from a directory, a directory at a git commit (PATH@REF), a .tgz file, - for stdin, or @PIPELINE/RESOURCE[@KEY:VALUE] (can be specified multiple times)"`
+	GitRef         string             `long:"git-ref"                   value-name:"REF"               description:"Upload the default input from a git commit rather than the working tree"`
+	Submodules     bool               `long:"submodules"                                               description:"Include submodules when uploading an input from a git commit"`
+	MaxUploadSize  ByteSizeFlag       `long:"max-upload-size"           value-name:"SIZE"              description:"Refuse to upload an input directory larger than this, e.g. 500MB (defaults to the target's max_upload_size in .flyrc)"`
+	Force          bool               `long:"force"                                                    description:"Upload inputs even if they are over the upload size limit"`
+	InputsFrom     JobFlag            `short:"j" long:"inputs-from"     value-name:"PIPELINE/JOB"      description:"A job to base the inputs on"`
+	Outputs        []OutputPairFlag   `short:"o" long:"output"          value-name:"NAME=PATH"         description:"An output to fetch from the task into a directory, a .tgz file, or - for stdout (can be specified multiple times)"`
+	OutputMode     string             `long:"output-mode"                                              default:"merge" choice:"clean" choice:"merge" choice:"fail" description:"How to handle output paths that already exist: replace them once downloaded (clean), extract on top of them (merge), or refuse to run (fail)"`
+	Var            []VariablePairFlag `short:"v" long:"var"             value-name:"[SECRET=KEY]"      description:"Variable flag that can be used for filling in template values in the task config"`
+	VarsFrom       []PathFlag         `short:"l" long:"load-vars-from"                                 description:"Variable flag that can be used for filling in template values in the task config from a YAML file"`
+	Params         []VariablePairFlag `long:"param"                     value-name:"KEY=VALUE"         description:"A param to set on the task (can be specified multiple times)"`
+	ParamsFrom     []PathFlag         `long:"params-from"                                              description:"Params to set on the task from a YAML file"`
+	Tags           []string           `long:"tag"                       value-name:"TAG"               description:"A worker tag to run every step of the build on (can be specified multiple times)"`
+	Image          string             `long:"image"                                                    description:"An image to run the task in, overriding the task config's image"`
+	Timeout        time.Duration      `long:"timeout"                   value-name:"DURATION"          description:"Abort the build if it has not finished within the given duration (e.g. 30m)"`
+	Attempts       int                `long:"attempts"                  value-name:"N"                 default:"1" description:"Run the build up to N times, until it finishes with a status not given by --retry-on"`
+	RetryOn        string             `long:"retry-on"                                                 default:"errored" choice:"errored" choice:"failed" choice:"any" description:"Which statuses to retry the build on: errored, failed, or any (aborted builds are never retried)"`
+	OnFinish       string             `long:"on-finish"                 value-name:"COMMAND"           description:"A command to run after the build finishes, with its details in FLY_BUILD_* environment variables"`
+	Detach         bool               `long:"detach"                                                   description:"Create the build and upload its inputs, then exit without waiting for it to finish"`
+	JSON           bool               `long:"json"                                                     description:"Print the detached build as JSON"`
+
+	RenderFlags
+
+	// the input that a job task's config file was read from, which is
+	// accepted with -i even if the task does not take it as an input
+	configInput string
 }
 
 func (command *ExecuteCommand) Execute(args []string) error {
+	if command.Detach && len(command.Outputs) > 0 {
+		return errors.New("outputs cannot be fetched from a detached build (remove --output or --detach)")
+	}
+
+	if command.JSON && !command.Detach {
+		return errors.New("--json can only be used with --detach")
+	}
+
+	if command.OutputFormat == "json" {
+		for _, o := range command.Outputs {
+			if o.Form == OutputStdout {
+				return errors.New("an output cannot be streamed to stdout along with --output-format json")
+			}
+		}
+	}
+
+	if command.Attempts < 1 {
+		return errors.New("--attempts must be at least 1")
+	}
+
+	if command.Detach && command.Attempts > 1 {
+		return errors.New("a detached build cannot be retried (remove --attempts or --detach)")
+	}
+
+	if command.Attempts > 1 {
+		for _, o := range command.Outputs {
+			if o.Form == OutputStdout {
+				return errors.New("an output streamed to stdout cannot be retried, as every attempt would write an archive to it (remove --attempts or write the output to a file)")
+			}
+		}
+	}
+
+	sources := 0
+	for _, given := range []bool{command.TaskConfig != "", command.JobTask.TaskName != "", command.Plan != ""} {
+		if given {
+			sources++
+		}
+	}
+
+	if sources == 0 {
+		return errors.New("one of --config, --job-task, or --plan must be specified")
+	}
+
+	if sources > 1 {
+		return errors.New("only one of --config, --job-task, or --plan can be specified")
+	}
+
+	if command.Plan != "" && len(args) > 0 {
+		return errors.New("arguments cannot be passed to the steps of a plan")
+	}
+
 	connection, err := rc.TargetConnection(Fly.Target)
 
 	if err != nil {
-		log.Fatalln(err)
+		fail(err)
 		return nil
 	}
 
 	client := concourse.NewClient(connection)
 
-	taskConfigFile := command.TaskConfig
 	excludeIgnored := command.ExcludeIgnored
 
 	atcRequester := newAtcRequester(connection.URL(), connection.HTTPClient())
 
-	taskConfig := config.LoadTaskConfig(string(taskConfigFile), args)
+	templateVariables := template.Variables{}
+	for _, v := range command.Var {
+		templateVariables[v.Name] = v.Value
+	}
+
+	templateVariables, err = loadTemplateVariables(command.VarsFrom, templateVariables)
+	if err != nil {
+		return err
+	}
+
+	params := template.Variables{}
+	for _, p := range command.Params {
+		params[p.Name] = p.Value
+	}
+
+	params, err = loadTemplateVariables(command.ParamsFrom, params)
+	if err != nil {
+		return err
+	}
+
+	tasks, jobInputMapping, err := command.taskPlans(client, args, templateVariables)
+	if err != nil {
+		return err
+	}
+
+	warnUnknownParams(params, tasks)
+
+	command.redactSecrets(templateVariables)
+	command.redactSecrets(params)
+	for _, task := range tasks {
+		command.redactSecrets(task.Config.Params)
+	}
+
+	for _, task := range tasks {
+		*task.Config = config.OverrideTaskParams(*task.Config, params)
+
+		if command.Image != "" {
+			task.Config.Image = command.Image
+		}
+
+		err = task.Config.Validate()
+		if err != nil && command.Plan != "" {
+			return fmt.Errorf("invalid config for step '%s': %s", task.Name, err)
+		} else if err != nil {
+			return err
+		}
+	}
+
+	inputMappings := command.Inputs
+
+	if command.GitRef != "" {
+		if len(inputMappings) > 0 || command.InputsFrom.PipelineName != "" || command.InputsFrom.JobName != "" {
+			return errors.New("--git-ref only applies to the default input (use -i NAME=PATH@REF instead)")
+		}
+
+		input, err := defaultInputMapping()
+		if err != nil {
+			return err
+		}
+
+		input.Ref = command.GitRef
+		inputMappings = []InputPairFlag{input}
+	}
+
+	if !command.Force {
+		limit, err := command.uploadLimit()
+		if err != nil {
+			return err
+		}
+
+		if limit > 0 {
+			localInputs, err := withDefaultInput(inputMappings, command.InputsFrom)
+			if err != nil {
+				return err
+			}
+
+			err = checkUploadSizes(os.Stderr, localInputs, limit, excludeIgnored)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	inputMappings, stdinBuffer, err := bufferStdinInputs(inputMappings)
+	if err != nil {
+		return err
+	}
+
+	if stdinBuffer != "" {
+		defer os.Remove(stdinBuffer)
+	}
+
+	if command.Detach {
+		build, inputs, _, err := command.startBuild(client, atcRequester, tasks, inputMappings, jobInputMapping, command.OutputMode)
+		if err != nil {
+			return err
+		}
+
+		// the build is only left to run once all of its inputs are uploaded
+		for _, i := range inputs {
+			if i.Path != "" {
+				err := upload(i, excludeIgnored, command.Submodules, atcRequester)
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		return printDetachedBuild(build, connection.URL(), command.JSON)
+	}
+
+	// keep stdout clean for an output being streamed to it
+	var renderTo io.Writer = os.Stdout
+	for _, o := range command.Outputs {
+		if o.Form == OutputStdout {
+			renderTo = os.Stderr
+		}
+	}
+
+	err = command.openLog()
+	if err != nil {
+		return err
+	}
+
+	renderTo = command.logged(renderTo)
+
+	noticesTo := command.noticeWriter(renderTo)
+
+	var exitCode int
+	attempts := []buildAttempt{}
+
+	for attempt := 1; attempt <= command.Attempts; attempt++ {
+		if command.Attempts > 1 {
+			fmt.Fprintf(noticesTo, "%s\n", color.New(color.Bold).Sprintf("attempt %d of %d", attempt, command.Attempts))
+		}
+
+		outputMode := command.OutputMode
+		if attempt > 1 && outputMode == "fail" {
+			// the paths were checked before the first attempt, which has since
+			// written its outputs to them
+			outputMode = "clean"
+		}
+
+		build, inputs, outputs, err := command.startBuild(client, atcRequester, tasks, inputMappings, jobInputMapping, outputMode)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintln(noticesTo, "executing build", build.ID)
+
+		var status atc.BuildStatus
+		exitCode, status = command.watchBuild(client, atcRequester, build, inputs, outputs, renderTo)
+
+		attempts = append(attempts, buildAttempt{Build: build, Status: status})
+
+		if !retryableStatus(command.RetryOn, status) {
+			break
+		}
+	}
+
+	if command.Attempts > 1 {
+		fmt.Fprintln(noticesTo, "")
+		printAttempts(noticesTo, attempts)
+	}
+
+	// only the final attempt is reported, as the ones before it were retried
+	last := attempts[len(attempts)-1]
+	finishHook{
+		command: command.OnFinish,
+		client:  client,
+		atcURL:  connection.URL(),
+	}.run(last.Build, last.Status)
+
+	if stdinBuffer != "" {
+		os.Remove(stdinBuffer)
+	}
+
+	os.Exit(exitCode)
+
+	return nil
+}
 
+// startBuild creates a build of the tasks along with fresh pipes for its
+// inputs and outputs.
+func (command *ExecuteCommand) startBuild(
+	client concourse.Client,
+	atcRequester *atcRequester,
+	tasks []atc.TaskPlan,
+	inputMappings []InputPairFlag,
+	jobInputMapping map[string]string,
+	outputMode string,
+) (atc.Build, []Input, []Output, error) {
 	inputs, err := determineInputs(
 		client,
-		taskConfig.Inputs,
-		command.Inputs,
+		planInputs(tasks),
+		inputMappings,
 		command.InputsFrom,
+		jobInputMapping,
+		command.configInput,
 	)
 	if err != nil {
-		return err
+		return atc.Build{}, nil, nil, err
 	}
 
 	outputs, err := determineOutputs(
 		client,
-		taskConfig.Outputs,
+		planOutputs(tasks),
 		command.Outputs,
+		outputMode,
 	)
 	if err != nil {
-		return err
+		return atc.Build{}, nil, nil, err
 	}
 
 	build, err := createBuild(
 		atcRequester,
 		client,
-		command.Privileged,
+		command.Tags,
 		inputs,
 		outputs,
-		taskConfig,
+		tasks,
 	)
 	if err != nil {
-		return err
+		return atc.Build{}, nil, nil, err
 	}
 
-	fmt.Println("executing build", build.ID)
+	return build, inputs, outputs, nil
+}
 
+// watchBuild uploads the build's inputs, renders its events, and downloads
+// its outputs, returning the exit code and final status of the build.
+func (command *ExecuteCommand) watchBuild(
+	client concourse.Client,
+	atcRequester *atcRequester,
+	build atc.Build,
+	inputs []Input,
+	outputs []Output,
+	renderTo io.Writer,
+) (int, atc.BuildStatus) {
 	terminate := make(chan os.Signal, 1)
 
 	go abortOnSignal(client, terminate, build)
 
 	signal.Notify(terminate, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(terminate)
 
 	go func() {
 		for _, i := range inputs {
 			if i.Path != "" {
-				upload(i, excludeIgnored, atcRequester)
+				err := upload(i, command.ExcludeIgnored, command.Submodules, atcRequester)
+				if err != nil {
+					fmt.Fprintln(os.Stderr, err)
+				}
 			}
 		}
 	}()
@@ -109,23 +396,124 @@ func (command *ExecuteCommand) Execute(args []string) error {
 		}
 	}
 
-	eventSource, err := client.BuildEvents(fmt.Sprintf("%d", build.ID))
-
+	eventSource, err := buildEvents(client, build)
 	if err != nil {
-		log.Println("failed to attach to stream:", err)
-		os.Exit(1)
+		failWithErrorf("failed to attach to stream", err)
 	}
 
-	exitCode := eventstream.Render(os.Stdout, eventSource)
+	stopTimeout := abortAfterTimeout(client, build, command.Timeout)
+
+	exitCode, status := command.render(renderTo, eventSource, command.Plan != "")
 	eventSource.Close()
 
+	exitCode = timeoutExitCode(stopTimeout(), status, exitCode)
+
 	if len(outputs) > 0 {
 		for _, outputChan := range outputChans {
 			<-outputChan
 		}
 	}
 
-	os.Exit(exitCode)
+	return exitCode, status
+}
+
+// uploadLimit determines the largest input directory that may be uploaded,
+// or 0 if there is no limit.
+func (command *ExecuteCommand) uploadLimit() (int64, error) {
+	if command.MaxUploadSize > 0 {
+		return int64(command.MaxUploadSize), nil
+	}
+
+	target, err := rc.SelectTarget(Fly.Target)
+	if err != nil {
+		return 0, err
+	}
+
+	if target.MaxUploadSize == "" {
+		return 0, nil
+	}
+
+	limit, err := parseByteSize(target.MaxUploadSize)
+	if err != nil {
+		return 0, fmt.Errorf("invalid max_upload_size for target '%s': %s", Fly.Target, err)
+	}
+
+	return limit, nil
+}
+
+func (command *ExecuteCommand) taskPlans(
+	client concourse.Client,
+	args []string,
+	templateVariables template.Variables,
+) ([]atc.TaskPlan, map[string]string, error) {
+	if command.Plan != "" {
+		tasks, err := localPlanTasks(string(command.Plan), templateVariables)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		for i := range tasks {
+			tasks[i].Privileged = tasks[i].Privileged || command.Privileged
+		}
+
+		return tasks, nil, nil
+	}
+
+	var taskConfig atc.TaskConfig
+	var inputMapping map[string]string
+
+	privileged := command.Privileged
+
+	if command.JobTask.TaskName != "" {
+		step, err := fetchJobTask(client, command.JobTask)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		taskConfig, command.configInput, err = jobTaskConfig(step, command.Inputs, templateVariables)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		taskConfig = config.OverrideTaskConfig(taskConfig, args)
+		privileged = privileged || step.Privileged
+		inputMapping = step.InputMapping
+	} else {
+		var err error
+		taskConfig, err = config.LoadTaskConfig(string(command.TaskConfig), templateVariables, args)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return []atc.TaskPlan{
+		{
+			Name:       "one-off",
+			Privileged: privileged,
+			Config:     &taskConfig,
+		},
+	}, inputMapping, nil
+}
+
+type detachedBuild struct {
+	ID  int    `json:"id"`
+	URL string `json:"url"`
+}
+
+func printDetachedBuild(build atc.Build, atcURL string, asJSON bool) error {
+	detached := detachedBuild{
+		ID:  build.ID,
+		URL: fmt.Sprintf("%s/builds/%d", atcURL, build.ID),
+	}
+
+	if asJSON {
+		return json.NewEncoder(os.Stdout).Encode(detached)
+	}
+
+	fmt.Println("started build", detached.ID)
+	fmt.Println("")
+	fmt.Printf("you can view the build here: %s\n", detached.URL)
+	fmt.Printf("or stream its output with: fly -t %s watch -b %d\n", Fly.Target, detached.ID)
 
 	return nil
 }
@@ -134,6 +522,8 @@ type Input struct {
 	Name string
 
 	Path string
+	Form InputForm
+	Ref  string
 	Pipe atc.Pipe
 
 	BuildInput atc.BuildInput
@@ -141,6 +531,8 @@ type Input struct {
 type Output struct {
 	Name string
 	Path string
+	Form OutputForm
+	Mode string
 	Pipe atc.Pipe
 }
 
@@ -148,9 +540,11 @@ func determineOutputs(
 	client concourse.Client,
 	taskOutputs []atc.TaskOutputConfig,
 	outputMappings []OutputPairFlag,
+	outputMode string,
 ) ([]Output, error) {
 
 	outputs := []Output{}
+	streamingToStdout := false
 
 	for _, i := range outputMappings {
 		outputName := i.Name
@@ -165,9 +559,26 @@ func determineOutputs(
 			return nil, fmt.Errorf("unknown output '%s'", outputName)
 		}
 
-		absPath, err := filepath.Abs(i.Path)
-		if err != nil {
-			return nil, err
+		path := i.Path
+		if i.Form == OutputStdout {
+			if streamingToStdout {
+				return nil, errors.New("only one output can be streamed to stdout")
+			}
+
+			streamingToStdout = true
+		} else {
+			absPath, err := filepath.Abs(i.Path)
+			if err != nil {
+				return nil, err
+			}
+
+			if outputMode == "fail" {
+				if _, err := os.Stat(absPath); err == nil {
+					return nil, fmt.Errorf("output path '%s' already exists", i.Path)
+				}
+			}
+
+			path = absPath
 		}
 
 		pipe, err := client.CreatePipe()
@@ -177,7 +588,9 @@ func determineOutputs(
 
 		outputs = append(outputs, Output{
 			Name: outputName,
-			Path: absPath,
+			Path: path,
+			Form: i.Form,
+			Mode: outputMode,
 			Pipe: pipe,
 		})
 	}
@@ -190,29 +603,31 @@ func determineInputs(
 	taskInputs []atc.TaskInputConfig,
 	inputMappings []InputPairFlag,
 	inputsFrom JobFlag,
+	jobInputMapping map[string]string,
+	configInput string,
 ) ([]Input, error) {
-	err := checkForUnknownInputMappings(inputMappings, taskInputs)
+	err := checkForUnknownInputMappings(inputMappings, taskInputs, configInput)
 	if err != nil {
 		return nil, err
 	}
 
-	if len(inputMappings) == 0 && inputsFrom.PipelineName == "" && inputsFrom.JobName == "" {
-		wd, err := os.Getwd()
-		if err != nil {
-			return nil, err
-		}
-
-		inputMappings = append(inputMappings, InputPairFlag{
-			Name: filepath.Base(wd),
-			Path: wd,
-		})
+	inputMappings, err = withDefaultInput(inputMappings, inputsFrom)
+	if err != nil {
+		return nil, err
 	}
 
+	inputMappings = taskInputMappings(inputMappings, taskInputs)
+
 	inputsFromLocal, err := generateLocalInputs(client, inputMappings)
 	if err != nil {
 		return nil, err
 	}
 
+	inputsFromResources, err := fetchInputsFromResources(client, inputMappings)
+	if err != nil {
+		return nil, err
+	}
+
 	inputsFromJob, err := fetchInputsFromJob(client, inputsFrom)
 	if err != nil {
 		return nil, err
@@ -222,10 +637,21 @@ func determineInputs(
 	for _, taskInput := range taskInputs {
 		input, found := inputsFromLocal[taskInput.Name]
 		if !found {
-			input, found = inputsFromJob[taskInput.Name]
+			input, found = inputsFromResources[taskInput.Name]
+		}
+
+		if !found {
+			jobInputName := taskInput.Name
+			if mapped, ok := jobInputMapping[taskInput.Name]; ok {
+				jobInputName = mapped
+			}
+
+			input, found = inputsFromJob[jobInputName]
 			if !found {
 				return nil, fmt.Errorf("missing required input `%s`", taskInput.Name)
 			}
+
+			input.Name = taskInput.Name
 		}
 
 		inputs = append(inputs, input)
@@ -234,8 +660,41 @@ func determineInputs(
 	return inputs, nil
 }
 
-func checkForUnknownInputMappings(inputMappings []InputPairFlag, validInputs []atc.TaskInputConfig) error {
+// withDefaultInput provides the working directory as an input if no other
+// inputs were given.
+func withDefaultInput(inputMappings []InputPairFlag, inputsFrom JobFlag) ([]InputPairFlag, error) {
+	if len(inputMappings) > 0 || inputsFrom.PipelineName != "" || inputsFrom.JobName != "" {
+		return inputMappings, nil
+	}
+
+	input, err := defaultInputMapping()
+	if err != nil {
+		return nil, err
+	}
+
+	return []InputPairFlag{input}, nil
+}
+
+// defaultInputMapping provides the working directory as the input named
+// after it.
+func defaultInputMapping() (InputPairFlag, error) {
+	wd, err := os.Getwd()
+	if err != nil {
+		return InputPairFlag{}, err
+	}
+
+	return InputPairFlag{
+		Name: filepath.Base(wd),
+		Path: wd,
+	}, nil
+}
+
+func checkForUnknownInputMappings(inputMappings []InputPairFlag, validInputs []atc.TaskInputConfig, configInput string) error {
 	for _, inputMapping := range inputMappings {
+		if inputMapping.Name == configInput {
+			continue
+		}
+
 		if !taskInputsContainsName(validInputs, inputMapping.Name) {
 			return fmt.Errorf("unknown input `%s`", inputMapping.Name)
 		}
@@ -243,6 +702,18 @@ func checkForUnknownInputMappings(inputMappings []InputPairFlag, validInputs []a
 	return nil
 }
 
+// taskInputMappings leaves out the inputs that the task does not take, i.e.
+// one that was only given to read a job task's config from.
+func taskInputMappings(inputMappings []InputPairFlag, taskInputs []atc.TaskInputConfig) []InputPairFlag {
+	mappings := []InputPairFlag{}
+	for _, inputMapping := range inputMappings {
+		if taskInputsContainsName(taskInputs, inputMapping.Name) {
+			mappings = append(mappings, inputMapping)
+		}
+	}
+	return mappings
+}
+
 func taskInputsContainsName(inputs []atc.TaskInputConfig, name string) bool {
 	for _, input := range inputs {
 		if input.Name == name {
@@ -256,9 +727,29 @@ func generateLocalInputs(client concourse.Client, inputMappings []InputPairFlag)
 	kvMap := map[string]Input{}
 
 	for _, i := range inputMappings {
+		if i.Resource.ResourceName != "" {
+			continue
+		}
+
 		inputName := i.Name
 		absPath := i.Path
 
+		if i.Form != InputDirectory {
+			err := validateArchive(absPath)
+			if err != nil {
+				return nil, fmt.Errorf("invalid archive for input `%s`: %s", inputName, err)
+			}
+		}
+
+		var commit string
+		if i.Ref != "" {
+			var err error
+			commit, err = resolveGitRef(absPath, i.Ref)
+			if err != nil {
+				return nil, fmt.Errorf("invalid git ref for input `%s`: %s", inputName, err)
+			}
+		}
+
 		pipe, err := client.CreatePipe()
 		if err != nil {
 			return nil, err
@@ -267,6 +758,8 @@ func generateLocalInputs(client concourse.Client, inputMappings []InputPairFlag)
 		kvMap[inputName] = Input{
 			Name: inputName,
 			Path: absPath,
+			Form: i.Form,
+			Ref:  commit,
 			Pipe: pipe,
 		}
 	}
@@ -274,6 +767,59 @@ func generateLocalInputs(client concourse.Client, inputMappings []InputPairFlag)
 	return kvMap, nil
 }
 
+func fetchInputsFromResources(client concourse.Client, inputMappings []InputPairFlag) (map[string]Input, error) {
+	kvMap := map[string]Input{}
+	pipelineConfigs := map[string]atc.Config{}
+
+	for _, i := range inputMappings {
+		if i.Resource.ResourceName == "" {
+			continue
+		}
+
+		pipelineName := i.Resource.PipelineName
+
+		config, fetched := pipelineConfigs[pipelineName]
+		if !fetched {
+			var found bool
+			var err error
+
+			config, _, found, err = client.PipelineConfig(pipelineName)
+			if err != nil {
+				return nil, err
+			}
+
+			if !found {
+				return nil, fmt.Errorf("pipeline '%s' not found", pipelineName)
+			}
+
+			pipelineConfigs[pipelineName] = config
+		}
+
+		resource, found := config.Resources.Lookup(i.Resource.ResourceName)
+		if !found {
+			return nil, fmt.Errorf("resource '%s' not found in pipeline '%s'", i.Resource.ResourceName, pipelineName)
+		}
+
+		version, err := resourceVersion(client, pipelineName, resource.Name, i.Version)
+		if err != nil {
+			return nil, err
+		}
+
+		kvMap[i.Name] = Input{
+			Name: i.Name,
+			BuildInput: atc.BuildInput{
+				Name:     i.Name,
+				Resource: resource.Name,
+				Type:     resource.Type,
+				Source:   resource.Source,
+				Version:  version,
+			},
+		}
+	}
+
+	return kvMap, nil
+}
+
 func fetchInputsFromJob(client concourse.Client, inputsFrom JobFlag) (map[string]Input, error) {
 	kvMap := map[string]Input{}
 	if inputsFrom.PipelineName == "" && inputsFrom.JobName == "" {
@@ -302,15 +848,11 @@ func fetchInputsFromJob(client concourse.Client, inputsFrom JobFlag) (map[string
 func createBuild(
 	atcRequester *atcRequester,
 	client concourse.Client,
-	privileged bool,
+	tags atc.Tags,
 	inputs []Input,
 	outputs []Output,
-	config atc.TaskConfig,
+	tasks []atc.TaskPlan,
 ) (atc.Build, error) {
-	if err := config.Validate(); err != nil {
-		return atc.Build{}, err
-	}
-
 	targetProps, err := rc.SelectTarget(Fly.Target)
 	if err != nil {
 		return atc.Build{}, err
@@ -340,6 +882,7 @@ func createBuild(
 				Name:   input.Name,
 				Type:   "archive",
 				Source: source,
+				Tags:   tags,
 			}
 		} else {
 			getPlan = atc.GetPlan{
@@ -350,6 +893,10 @@ func createBuild(
 				Params:  input.BuildInput.Params,
 				Tags:    input.BuildInput.Tags,
 			}
+
+			if len(tags) > 0 {
+				getPlan.Tags = tags
+			}
 		}
 
 		buildInputs = append(buildInputs, atc.Plan{
@@ -363,19 +910,34 @@ func createBuild(
 		})
 	}
 
-	taskPlan := atc.Plan{
-		Location: &atc.Location{
-			// offset by 1 because aggregate gets parallelgroup ID 1
-			ID:       uint(len(inputs)) + 2,
-			ParentID: 0,
-		},
-		Task: &atc.TaskPlan{
-			Name:       "one-off",
-			Privileged: privileged,
-			Config:     &config,
-		},
+	taskPlans := []atc.Plan{}
+	for i, task := range tasks {
+		taskPlan := task
+		taskPlan.Tags = tags
+
+		taskPlans = append(taskPlans, atc.Plan{
+			Location: &atc.Location{
+				// offset by 2 because aggregate gets parallelgroup ID 1
+				ID:       uint(len(inputs)) + 2 + uint(i),
+				ParentID: 0,
+			},
+			Task: &taskPlan,
+		})
 	}
 
+	// run the tasks in order, stopping at the first one that doesn't succeed
+	taskPlan := taskPlans[len(taskPlans)-1]
+	for i := len(taskPlans) - 2; i >= 0; i-- {
+		taskPlan = atc.Plan{
+			OnSuccess: &atc.OnSuccessPlan{
+				Step: taskPlans[i],
+				Next: taskPlan,
+			},
+		}
+	}
+
+	lastTaskID := taskPlans[len(taskPlans)-1].Location.ID
+
 	buildOutputs := atc.AggregatePlan{}
 	for i, output := range outputs {
 		writePipe, err := atcRequester.CreateRequest(
@@ -400,15 +962,16 @@ func createBuild(
 
 		buildOutputs = append(buildOutputs, atc.Plan{
 			Location: &atc.Location{
-				ID:            taskPlan.Location.ID + 2 + uint(i),
+				ID:            lastTaskID + 2 + uint(i),
 				ParentID:      0,
-				ParallelGroup: taskPlan.Location.ID + 1,
+				ParallelGroup: lastTaskID + 1,
 			},
 			Put: &atc.PutPlan{
 				Name:   output.Name,
 				Type:   "archive",
 				Source: source,
 				Params: params,
+				Tags:   tags,
 			},
 		})
 	}
@@ -462,30 +1025,45 @@ func abortOnSignal(
 	// if told to terminate again, exit immediately
 	<-terminate
 	fmt.Fprintln(os.Stderr, "exiting immediately")
-	os.Exit(2)
+	os.Exit(ExitAborted)
 }
 
-func upload(input Input, excludeIgnored bool, atcRequester *atcRequester) {
+func upload(input Input, excludeIgnored bool, submodules bool, atcRequester *atcRequester) error {
 	path := input.Path
 	pipe := input.Pipe
 
-	var files []string
+	var archive io.ReadCloser
 	var err error
 
-	if excludeIgnored {
-		files, err = getGitFiles(path)
+	switch {
+	case input.Ref != "":
+		archive, err = gitArchiveStreamFrom(path, input.Ref, submodules)
 		if err != nil {
-			fmt.Fprintln(os.Stderr, "could not determine ignored files:", err)
-			return
+			return fmt.Errorf("could not create git archive: %s", err)
 		}
-	} else {
-		files = []string{"."}
-	}
 
-	archive, err := tarStreamFrom(path, files)
-	if err != nil {
-		fmt.Fprintln(os.Stderr, "could create tar stream:", err)
-		return
+	case input.Form == InputArchive:
+		archive, err = os.Open(path)
+		if err != nil {
+			return fmt.Errorf("could not open archive: %s", err)
+		}
+
+	default:
+		var files []string
+
+		if excludeIgnored {
+			files, err = getGitFiles(path)
+			if err != nil {
+				return fmt.Errorf("could not determine ignored files: %s", err)
+			}
+		} else {
+			files = []string{"."}
+		}
+
+		archive, err = tarStreamFrom(path, files)
+		if err != nil {
+			return fmt.Errorf("could create tar stream: %s", err)
+		}
 	}
 
 	defer archive.Close()
@@ -496,19 +1074,21 @@ func upload(input Input, excludeIgnored bool, atcRequester *atcRequester) {
 		archive,
 	)
 	if err != nil {
-		panic(err)
+		return fmt.Errorf("failed to create upload request: %s", err)
 	}
 
 	response, err := atcRequester.httpClient.Do(uploadBits)
 	if err != nil {
-		fmt.Fprintln(os.Stderr, "upload request failed:", err)
+		return fmt.Errorf("upload request failed: %s", err)
 	}
 
 	defer response.Body.Close()
 
 	if response.StatusCode != http.StatusOK {
-		fmt.Fprintln(os.Stderr, badResponseError("uploading bits", response))
+		return badResponseError("uploading bits", response)
 	}
+
+	return nil
 }
 
 func download(output Output, atcRequester *atcRequester) {
@@ -521,30 +1101,125 @@ func download(output Output, atcRequester *atcRequester) {
 		nil,
 	)
 	if err != nil {
-		panic(err)
+		failWithErrorf("failed to create download request", err)
 	}
 
 	response, err := atcRequester.httpClient.Do(downloadBits)
 	if err != nil {
-		fmt.Fprintln(os.Stderr, "download request failed:", err)
+		failWithErrorf("download request failed", err)
 	}
 
 	defer response.Body.Close()
 
 	if response.StatusCode != http.StatusOK {
-		fmt.Fprintln(os.Stderr, badResponseError("downloading bits", response))
-		panic("unexpected-response-code")
+		fail(badResponseError("downloading bits", response))
+	}
+
+	switch output.Form {
+	case OutputStdout:
+		_, err = io.Copy(os.Stdout, response.Body)
+	case OutputArchive:
+		err = saveArchive(path, output.Mode, response.Body)
+	default:
+		err = extractArchive(path, output.Mode, response.Body)
 	}
 
-	err = os.MkdirAll(path, 0755)
 	if err != nil {
-		panic(err)
+		failWithErrorf("failed to save output `%s`", err, output.Name)
 	}
+}
 
-	err = tarStreamTo(path, response.Body)
+func saveArchive(path string, mode string, stream io.Reader) error {
+	err := os.MkdirAll(filepath.Dir(path), 0755)
 	if err != nil {
-		panic(err)
+		return err
+	}
+
+	if mode != "clean" {
+		archive, err := os.Create(path)
+		if err != nil {
+			return err
+		}
+
+		defer archive.Close()
+
+		_, err = io.Copy(archive, stream)
+		return err
 	}
+
+	archive, err := ioutil.TempFile(filepath.Dir(path), "."+filepath.Base(path)+"-")
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(archive, stream)
+	archive.Close()
+
+	if err != nil {
+		os.Remove(archive.Name())
+		return err
+	}
+
+	return os.Rename(archive.Name(), path)
+}
+
+func extractArchive(path string, mode string, stream io.Reader) error {
+	if mode != "clean" {
+		err := os.MkdirAll(path, 0755)
+		if err != nil {
+			return err
+		}
+
+		return tarStreamTo(path, stream)
+	}
+
+	parent := filepath.Dir(path)
+
+	err := os.MkdirAll(parent, 0755)
+	if err != nil {
+		return err
+	}
+
+	// extract next to the destination so that it can be renamed into place
+	extracted, err := ioutil.TempDir(parent, "."+filepath.Base(path)+"-")
+	if err != nil {
+		return err
+	}
+
+	err = tarStreamTo(extracted, stream)
+	if err == nil {
+		err = os.Chmod(extracted, 0755)
+	}
+
+	if err != nil {
+		os.RemoveAll(extracted)
+		return err
+	}
+
+	return swapDirectory(extracted, path)
+}
+
+func swapDirectory(src string, dst string) error {
+	if _, err := os.Stat(dst); os.IsNotExist(err) {
+		return os.Rename(src, dst)
+	}
+
+	backup := src + "-old"
+
+	err := os.Rename(dst, backup)
+	if err != nil {
+		os.RemoveAll(src)
+		return err
+	}
+
+	err = os.Rename(src, dst)
+	if err != nil {
+		os.Rename(backup, dst)
+		os.RemoveAll(src)
+		return err
+	}
+
+	return os.RemoveAll(backup)
 }
 
 func getGitFiles(dir string) ([]string, error) {
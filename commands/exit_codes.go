@@ -0,0 +1,97 @@
+package commands
+
+import (
+	"strings"
+
+	"github.com/concourse/atc"
+)
+
+// Every command exits with one of these codes. The first few mirror how a
+// build finished, so that scripts can tell a failing task from a build that
+// errored, and both from fly itself being unable to do its job.
+const (
+	ExitSucceeded = 0
+	ExitFailed    = 1
+	ExitErrored   = 2
+	ExitAborted   = 3
+	ExitTimedOut  = 4
+
+	// fly could not do what it was asked, e.g. due to bad flags or config, an
+	// unreachable ATC, or an unexpected response
+	ExitClientError = 10
+
+	// the ATC rejected fly's credentials
+	ExitAuthError = 11
+)
+
+// ExitCodeForError determines how fly should exit after failing with the
+// given error.
+func ExitCodeForError(err error) int {
+	if isAuthError(err) {
+		return ExitAuthError
+	}
+
+	return ExitClientError
+}
+
+// isAuthError determines whether the ATC rejected the request that failed.
+// go-concourse does not expose the status of an unexpected response, only
+// its message, so for its errors the only way to tell is by the status that
+// the message includes.
+func isAuthError(err error) bool {
+	if response, ok := err.(badResponse); ok {
+		return isAuthStatus(response.statusCode)
+	}
+
+	message := err.Error()
+	return strings.Contains(message, "401 Unauthorized") || strings.Contains(message, "403 Forbidden")
+}
+
+// buildExitCode determines the exit code for a build from the status it
+// finished with, falling back on the code that rendering its events returned
+// if it never reported one.
+func buildExitCode(status atc.BuildStatus, renderExitCode int) int {
+	switch status {
+	case atc.StatusSucceeded:
+		return ExitSucceeded
+	case atc.StatusFailed:
+		return ExitFailed
+	case atc.StatusErrored:
+		return ExitErrored
+	case atc.StatusAborted:
+		return ExitAborted
+	}
+
+	if renderExitCode == 0 {
+		return ExitSucceeded
+	}
+
+	return ExitClientError
+}
+
+// from least to most severe, for picking the code to exit with when several
+// builds were watched at once
+var exitCodeSeverity = []int{
+	ExitSucceeded,
+	ExitAborted,
+	ExitFailed,
+	ExitTimedOut,
+	ExitErrored,
+	ExitClientError,
+	ExitAuthError,
+}
+
+// worstExitCode returns the most severe of the given exit codes.
+func worstExitCode(codes ...int) int {
+	worst, worstSeverity := ExitSucceeded, 0
+
+	for _, code := range codes {
+		for severity, known := range exitCodeSeverity {
+			if code == known && severity > worstSeverity {
+				worst, worstSeverity = code, severity
+			}
+		}
+	}
+
+	return worst
+}
@@ -8,8 +8,10 @@ type FlyCommand struct {
 
 	Checklist ChecklistCommand `command:"checklist" alias:"cl" description:"Print a Checkfile of the given pipeline"`
 
-	Execute ExecuteCommand `command:"execute" alias:"e" description:"Execute a one-off build using local bits"`
-	Watch   WatchCommand   `command:"watch"   alias:"w" description:"Stream a build's output"`
+	Execute      ExecuteCommand      `command:"execute"       alias:"e"  description:"Execute a one-off build using local bits"`
+	ValidateTask ValidateTaskCommand `command:"validate-task" alias:"vt" description:"Validate a task config without running it"`
+	Watch        WatchCommand        `command:"watch"         alias:"w"  description:"Stream a build's output"`
+	BuildLogs    BuildLogsCommand    `command:"build-logs"    alias:"bl" description:"Download a finished build's output"`
 
 	Containers ContainersCommand `command:"containers" alias:"cs" description:"Print the active containers"`
 	Hijack     HijackCommand     `command:"hijack"     alias:"intercept" alias:"i" description:"Execute a command in a container"`
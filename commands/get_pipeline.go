@@ -3,8 +3,6 @@ package commands
 import (
 	"encoding/json"
 	"fmt"
-	"log"
-	"os"
 
 	"gopkg.in/yaml.v2"
 
@@ -24,13 +22,13 @@ func (command *GetPipelineCommand) Execute(args []string) error {
 
 	connection, err := rc.TargetConnection(Fly.Target)
 	if err != nil {
-		log.Fatalln(err)
+		fail(err)
 	}
 
 	client := concourse.NewClient(connection)
 	config, _, _, err := client.PipelineConfig(pipelineName)
 	if err != nil {
-		log.Fatalln(err)
+		fail(err)
 	}
 
 	dump(config, asJSON)
@@ -47,8 +45,7 @@ func dump(config atc.Config, asJSON bool) {
 	}
 
 	if err != nil {
-		log.Println("failed to marshal config to YAML:", err)
-		os.Exit(1)
+		failWithErrorf("failed to marshal config", err)
 	}
 
 	fmt.Printf("%s", payload)
@@ -0,0 +1,174 @@
+package commands
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// resolveGitRef resolves the ref to a commit, so that the same tree is
+// uploaded even if the ref moves while fly is running.
+func resolveGitRef(dir string, ref string) (string, error) {
+	revParse := exec.Command("git", "rev-parse", "--verify", "--quiet", ref+"^{commit}")
+	revParse.Dir = dir
+
+	output, err := revParse.Output()
+	if err != nil {
+		return "", fmt.Errorf("'%s' is not a commit in %s", ref, dir)
+	}
+
+	return strings.TrimSpace(string(output)), nil
+}
+
+// gitArchiveStreamFrom streams the tree of the given commit as a gzipped
+// tarball, as git archive would, optionally including the commits of its
+// submodules.
+func gitArchiveStreamFrom(dir string, commit string, submodules bool) (io.ReadCloser, error) {
+	r, w := io.Pipe()
+
+	go func() {
+		gzWriter := gzip.NewWriter(w)
+		tarWriter := tar.NewWriter(gzWriter)
+
+		err := writeGitArchive(tarWriter, dir, commit, "", submodules)
+		if err == nil {
+			err = tarWriter.Close()
+		}
+
+		if err == nil {
+			err = gzWriter.Close()
+		}
+
+		w.CloseWithError(err)
+	}()
+
+	return r, nil
+}
+
+func writeGitArchive(tw *tar.Writer, dir string, commit string, prefix string, submodules bool) error {
+	stderr := new(bytes.Buffer)
+
+	archiveCmd := exec.Command("git", "archive", "--format=tar", "--prefix="+prefix, commit)
+	archiveCmd.Dir = dir
+	archiveCmd.Stderr = stderr
+
+	archive, err := archiveCmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+
+	err = archiveCmd.Start()
+	if err != nil {
+		return err
+	}
+
+	tr := tar.NewReader(archive)
+
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			archiveCmd.Wait()
+			return err
+		}
+
+		// the commit ID git records in a global header isn't a file, and the
+		// submodule's directory was already archived by its parent
+		if hdr.Typeflag == tar.TypeXGlobalHeader || (prefix != "" && hdr.Name == prefix) {
+			continue
+		}
+
+		err = tw.WriteHeader(hdr)
+		if err != nil {
+			archiveCmd.Wait()
+			return err
+		}
+
+		_, err = io.Copy(tw, tr)
+		if err != nil {
+			archiveCmd.Wait()
+			return err
+		}
+	}
+
+	err = archiveCmd.Wait()
+	if err != nil {
+		return fmt.Errorf("git archive failed in %s: %s", dir, strings.TrimSpace(stderr.String()))
+	}
+
+	if !submodules {
+		return nil
+	}
+
+	modules, err := gitSubmodules(dir, commit)
+	if err != nil {
+		return err
+	}
+
+	for path, subcommit := range modules {
+		subdir := filepath.Join(dir, filepath.FromSlash(path))
+
+		if _, err := os.Stat(filepath.Join(subdir, ".git")); err != nil {
+			return fmt.Errorf("submodule '%s' is not initialized", path)
+		}
+
+		err = writeGitArchive(tw, subdir, subcommit, prefix+path+"/", true)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// gitSubmodules lists the commits that the tree of the given commit pins its
+// submodules to, by path.
+func gitSubmodules(dir string, commit string) (map[string]string, error) {
+	modules := map[string]string{}
+
+	lsTree := exec.Command("git", "ls-tree", "-r", "-z", commit)
+	lsTree.Dir = dir
+
+	lsOut, err := lsTree.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+
+	outScan := bufio.NewScanner(lsOut)
+	outScan.Split(scanNull)
+
+	err = lsTree.Start()
+	if err != nil {
+		return nil, err
+	}
+
+	for outScan.Scan() {
+		// <mode> SP <type> SP <object> TAB <file>
+		entry := strings.SplitN(outScan.Text(), "\t", 2)
+		if len(entry) != 2 {
+			continue
+		}
+
+		fields := strings.Fields(entry[0])
+		if len(fields) == 3 && fields[1] == "commit" {
+			modules[entry[1]] = fields[2]
+		}
+	}
+
+	err = lsTree.Wait()
+	if err != nil {
+		return nil, err
+	}
+
+	return modules, nil
+}
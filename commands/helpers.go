@@ -4,7 +4,6 @@ import (
 	"errors"
 	"fmt"
 	"io/ioutil"
-	"log"
 	"net/http"
 	"os"
 
@@ -16,17 +15,24 @@ func handleBadResponse(process string, resp *http.Response) {
 	b, err := ioutil.ReadAll(resp.Body)
 	resp.Body.Close()
 	if err != nil {
-		log.Fatalln("failed to read response when %s:", process, err)
+		failWithErrorf("failed to read response when %s", err, process)
 	}
-	log.Fatalf("bad response when %s:\n%s\n%s", process, resp.Status, b)
+
+	fmt.Fprintf(os.Stderr, "bad response when %s:\n%s\n%s\n", process, resp.Status, b)
+
+	if isAuthStatus(resp.StatusCode) {
+		os.Exit(ExitAuthError)
+	}
+
+	os.Exit(ExitClientError)
 }
 
 func GetBuild(client concourse.Client, jobName string, buildNameOrID string, pipelineName string) (atc.Build, error) {
 	if pipelineName != "" && jobName == "" {
-		log.Fatalln("job must be specified if pipeline is specified")
+		failf("job must be specified if pipeline is specified")
 	}
 	if pipelineName == "" && jobName != "" {
-		log.Fatalln("pipeline must be specified if job is specified")
+		failf("pipeline must be specified if job is specified")
 	}
 
 	if buildNameOrID != "" {
@@ -83,12 +89,18 @@ func GetBuild(client concourse.Client, jobName string, buildNameOrID string, pip
 	}
 }
 
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(ExitCodeForError(err))
+}
+
 func failf(message string, args ...interface{}) {
 	fmt.Fprintf(os.Stderr, message+"\n", args...)
-	os.Exit(1)
+	os.Exit(ExitClientError)
 }
 
 func failWithErrorf(message string, err error, args ...interface{}) {
 	templatedMessage := fmt.Sprintf(message, args...)
-	failf("%s: %s", templatedMessage, err.Error())
+	fmt.Fprintf(os.Stderr, "%s: %s\n", templatedMessage, err.Error())
+	os.Exit(ExitCodeForError(err))
 }
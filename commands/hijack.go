@@ -7,7 +7,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"log"
 	"net"
 	"net/http"
 	"net/http/httputil"
@@ -119,7 +118,7 @@ func locateContainer(client concourse.Client, fingerprint containerFingerprint)
 func constructRequest(reqGenerator *rata.RequestGenerator, spec atc.HijackProcessSpec, id string, token *rc.TargetToken) *http.Request {
 	payload, err := json.Marshal(spec)
 	if err != nil {
-		log.Fatalln("failed to marshal process spec:", err)
+		failWithErrorf("failed to marshal process spec", err)
 	}
 
 	hijackReq, err := reqGenerator.CreateRequest(
@@ -128,7 +127,7 @@ func constructRequest(reqGenerator *rata.RequestGenerator, spec atc.HijackProces
 		bytes.NewBuffer(payload),
 	)
 	if err != nil {
-		log.Fatalln("failed to create hijack request:", err)
+		failWithErrorf("failed to create hijack request", err)
 	}
 
 	if token != nil {
@@ -161,18 +160,18 @@ func getContainerIDs(c *HijackCommand) []atc.Container {
 
 	connection, err := rc.TargetConnection(Fly.Target)
 	if err != nil {
-		log.Fatalln("failed to create client:", err)
+		failWithErrorf("failed to create client", err)
 	}
 	client := concourse.NewClient(connection)
 
 	reqValues, err := locateContainer(client, fingerprint)
 	if err != nil {
-		log.Fatalln(err)
+		fail(err)
 	}
 
 	containers, err := client.ListContainers(reqValues)
 	if err != nil {
-		log.Fatalln("failed to get containers:", err)
+		failWithErrorf("failed to get containers", err)
 	}
 	return containers
 }
@@ -180,7 +179,7 @@ func getContainerIDs(c *HijackCommand) []atc.Container {
 func (command *HijackCommand) Execute(args []string) error {
 	target, err := rc.SelectTarget(Fly.Target)
 	if err != nil {
-		log.Fatalln(err)
+		fail(err)
 		return nil
 	}
 
@@ -189,7 +188,7 @@ func (command *HijackCommand) Execute(args []string) error {
 	var id string
 	if len(containers) == 0 {
 		fmt.Fprintln(os.Stderr, "no containers matched your search parameters! they may have expired if your build hasn't recently finished")
-		os.Exit(1)
+		os.Exit(ExitClientError)
 	} else if len(containers) > 1 {
 		var choices []interact.Choice
 		for _, container := range containers {
@@ -260,14 +259,14 @@ func (command *HijackCommand) Execute(args []string) error {
 func performHijack(hijackReq *http.Request, tlsConfig *tls.Config) int {
 	conn, err := dialEndpoint(hijackReq.URL, tlsConfig)
 	if err != nil {
-		log.Fatalln("failed to dial hijack endpoint:", err)
+		failWithErrorf("failed to dial hijack endpoint", err)
 	}
 
 	clientConn := httputil.NewClientConn(conn, nil)
 
 	resp, err := clientConn.Do(hijackReq)
 	if err != nil {
-		log.Fatalln("failed to hijack:", err)
+		failWithErrorf("failed to hijack", err)
 	}
 
 	if resp.StatusCode != http.StatusOK {
@@ -378,7 +377,7 @@ func canonicalAddr(url *url.URL) string {
 			host = url.Host
 			port = canonicalPortMap[url.Scheme]
 		} else {
-			log.Fatalln("invalid host:", err)
+			failWithErrorf("invalid host", err)
 		}
 	}
 
@@ -0,0 +1,98 @@
+package commands
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+)
+
+// bufferStdinInputs saves an archive piped to fly so that it can be validated
+// before the build is created, and uploaded again if the build is retried.
+// The input is replaced with the saved archive, whose path is returned for
+// the caller to remove.
+func bufferStdinInputs(inputMappings []InputPairFlag) ([]InputPairFlag, string, error) {
+	readingStdin := false
+	for _, i := range inputMappings {
+		if i.Form == InputStdin {
+			if readingStdin {
+				return nil, "", errors.New("only one input can be read from stdin")
+			}
+
+			readingStdin = true
+		}
+	}
+
+	if !readingStdin {
+		return inputMappings, "", nil
+	}
+
+	bufferPath, err := bufferStdin()
+	if err != nil {
+		return nil, "", err
+	}
+
+	buffered := []InputPairFlag{}
+	for _, i := range inputMappings {
+		if i.Form == InputStdin {
+			i.Path = bufferPath
+			i.Form = InputArchive
+		}
+
+		buffered = append(buffered, i)
+	}
+
+	return buffered, bufferPath, nil
+}
+
+func bufferStdin() (string, error) {
+	buffer, err := ioutil.TempFile("", "fly-input-")
+	if err != nil {
+		return "", err
+	}
+
+	defer buffer.Close()
+
+	_, err = io.Copy(buffer, os.Stdin)
+	if err != nil {
+		os.Remove(buffer.Name())
+		return "", err
+	}
+
+	return buffer.Name(), nil
+}
+
+func validateArchive(path string) error {
+	archive, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+
+	defer archive.Close()
+
+	gr, err := gzip.NewReader(archive)
+	if err != nil {
+		return fmt.Errorf("not a gzipped tarball: %s", err)
+	}
+
+	tr := tar.NewReader(gr)
+
+	for {
+		_, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+
+		if err != nil {
+			return fmt.Errorf("not a gzipped tarball: %s", err)
+		}
+
+		_, err = io.Copy(ioutil.Discard, tr)
+		if err != nil {
+			return fmt.Errorf("not a gzipped tarball: %s", err)
+		}
+	}
+}
@@ -4,11 +4,26 @@ import (
 	"fmt"
 	"path/filepath"
 	"strings"
+
+	"github.com/concourse/atc"
+)
+
+type InputForm int
+
+const (
+	InputDirectory InputForm = iota
+	InputArchive
+	InputStdin
 )
 
 type InputPairFlag struct {
 	Name string
 	Path string
+	Form InputForm
+	Ref  string
+
+	Resource ResourceFlag
+	Version  atc.Version
 }
 
 func (pair *InputPairFlag) UnmarshalFlag(value string) error {
@@ -17,21 +32,89 @@ func (pair *InputPairFlag) UnmarshalFlag(value string) error {
 		return fmt.Errorf("invalid input pair '%s' (must be name=path)", value)
 	}
 
-	matches, err := filepath.Glob(vs[1])
+	if strings.HasPrefix(vs[1], "@") {
+		pair.Name = vs[0]
+		return pair.unmarshalResource(strings.TrimPrefix(vs[1], "@"))
+	}
+
+	if vs[1] == "-" {
+		pair.Name = vs[0]
+		pair.Path = vs[1]
+		pair.Form = InputStdin
+		return nil
+	}
+
+	path := vs[1]
+	ref := ""
+
+	matches, err := filepath.Glob(path)
 	if err != nil {
-		return fmt.Errorf("failed to expand path '%s': %s", vs[1], err)
+		return fmt.Errorf("failed to expand path '%s': %s", path, err)
+	}
+
+	// only treat a trailing @ as a ref if the path doesn't exist with it
+	if len(matches) == 0 && strings.Contains(path, "@") {
+		at := strings.LastIndex(path, "@")
+		path, ref = path[:at], path[at+1:]
+
+		if ref == "" {
+			return fmt.Errorf("invalid input pair '%s' (must be name=path@ref)", value)
+		}
+
+		matches, err = filepath.Glob(path)
+		if err != nil {
+			return fmt.Errorf("failed to expand path '%s': %s", path, err)
+		}
 	}
 
 	if len(matches) == 0 {
-		return fmt.Errorf("path '%s' does not exist", vs[1])
+		return fmt.Errorf("path '%s' does not exist", path)
 	}
 
 	if len(matches) > 1 {
-		return fmt.Errorf("path '%s' resolves to multiple entries: %s", vs[1], strings.Join(matches, ", "))
+		return fmt.Errorf("path '%s' resolves to multiple entries: %s", path, strings.Join(matches, ", "))
 	}
 
 	pair.Name = vs[0]
 	pair.Path = matches[0]
+	pair.Ref = ref
+
+	if strings.HasSuffix(pair.Path, ".tgz") || strings.HasSuffix(pair.Path, ".tar.gz") {
+		if ref != "" {
+			return fmt.Errorf("a git ref cannot be given for archive '%s'", pair.Path)
+		}
+
+		pair.Form = InputArchive
+	}
+
+	return nil
+}
+
+func (pair *InputPairFlag) unmarshalResource(value string) error {
+	vs := strings.SplitN(value, "@", 2)
+	if !strings.Contains(vs[0], "/") {
+		return fmt.Errorf("invalid resource '%s' (must be @pipeline/resource[@key:value])", value)
+	}
+
+	err := pair.Resource.UnmarshalFlag(vs[0])
+	if err != nil {
+		return err
+	}
+
+	if len(vs) == 1 {
+		return nil
+	}
+
+	pair.Version = atc.Version{}
+
+	for _, kv := range strings.Split(vs[1], ",") {
+		kvs := strings.SplitN(kv, ":", 2)
+		if len(kvs) != 2 || kvs[0] == "" {
+			return fmt.Errorf("invalid version '%s' (must be key:value[,key:value])", vs[1])
+		}
+
+		pair.Version[kvs[0]] = kvs[1]
+	}
 
 	return nil
 }
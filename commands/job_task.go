@@ -0,0 +1,149 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/concourse/atc"
+	"github.com/concourse/fly/config"
+	"github.com/concourse/fly/template"
+	"github.com/concourse/go-concourse/concourse"
+)
+
+func fetchJobTask(client concourse.Client, jobTask JobTaskFlag) (atc.PlanConfig, error) {
+	pipelineConfig, _, found, err := client.PipelineConfig(jobTask.PipelineName)
+	if err != nil {
+		return atc.PlanConfig{}, err
+	}
+
+	if !found {
+		return atc.PlanConfig{}, fmt.Errorf("pipeline '%s' not found", jobTask.PipelineName)
+	}
+
+	job, found := pipelineConfig.Jobs.Lookup(jobTask.JobName)
+	if !found {
+		return atc.PlanConfig{}, fmt.Errorf("job '%s' not found in pipeline '%s'", jobTask.JobName, jobTask.PipelineName)
+	}
+
+	step, found := findTaskStep(job.Plan, jobTask.TaskName)
+	if !found {
+		return atc.PlanConfig{}, fmt.Errorf("task '%s' not found in job '%s'", jobTask.TaskName, jobTask.JobName)
+	}
+
+	return step, nil
+}
+
+func findTaskStep(plan atc.PlanSequence, taskName string) (atc.PlanConfig, bool) {
+	for _, step := range plan {
+		if step.Task == taskName {
+			return step, true
+		}
+
+		var nested atc.PlanSequence
+
+		if step.Do != nil {
+			nested = append(nested, *step.Do...)
+		}
+
+		if step.Aggregate != nil {
+			nested = append(nested, *step.Aggregate...)
+		}
+
+		for _, hook := range []*atc.PlanConfig{step.Try, step.Success, step.Failure, step.Ensure} {
+			if hook != nil {
+				nested = append(nested, *hook)
+			}
+		}
+
+		if found, ok := findTaskStep(nested, taskName); ok {
+			return found, true
+		}
+	}
+
+	return atc.PlanConfig{}, false
+}
+
+// jobTaskConfig builds the config of a job's task step, returning it along
+// with the name of the input that its file was read from, if any.
+func jobTaskConfig(step atc.PlanConfig, inputMappings []InputPairFlag, templateVariables template.Variables) (atc.TaskConfig, string, error) {
+	var taskConfig atc.TaskConfig
+	var configInput string
+
+	if step.TaskConfigPath != "" {
+		var configPath string
+		var err error
+
+		configPath, configInput, err = resolveTaskConfigPath(step.TaskConfigPath, inputMappings, step.InputMapping)
+		if err != nil {
+			return atc.TaskConfig{}, "", err
+		}
+
+		taskConfig, err = config.ReadTaskConfig(configPath, templateVariables)
+		if err != nil {
+			return atc.TaskConfig{}, "", err
+		}
+	}
+
+	if step.TaskConfig != nil {
+		taskConfig = taskConfig.Merge(*step.TaskConfig)
+	}
+
+	if step.TaskConfigPath == "" && step.TaskConfig == nil {
+		return atc.TaskConfig{}, "", fmt.Errorf("task '%s' has neither a config nor a file", step.Task)
+	}
+
+	if len(step.Params) > 0 && taskConfig.Params == nil {
+		taskConfig.Params = map[string]string{}
+	}
+
+	for name, value := range step.Params {
+		if str, ok := value.(string); ok {
+			taskConfig.Params[name] = str
+			continue
+		}
+
+		payload, err := json.Marshal(value)
+		if err != nil {
+			return atc.TaskConfig{}, "", fmt.Errorf("invalid value for param '%s': %s", name, err)
+		}
+
+		taskConfig.Params[name] = string(payload)
+	}
+
+	return taskConfig, configInput, nil
+}
+
+// resolveTaskConfigPath finds the task's config file within the local input
+// that provides the artifact it is in. The input may be named after the
+// artifact itself, or after a task input that the step's input_mapping maps
+// to it.
+func resolveTaskConfigPath(taskConfigPath string, inputMappings []InputPairFlag, inputMapping map[string]string) (string, string, error) {
+	segments := strings.SplitN(taskConfigPath, "/", 2)
+	if len(segments) != 2 {
+		return "", "", fmt.Errorf("task config file '%s' is not within an input", taskConfigPath)
+	}
+
+	artifact := segments[0]
+
+	names := []string{artifact}
+	for taskInput, mapped := range inputMapping {
+		if mapped == artifact {
+			names = append(names, taskInput)
+		}
+	}
+
+	for _, i := range inputMappings {
+		if containsString(names, i.Name) && i.Path != "" && i.Form == InputDirectory {
+			return filepath.Join(i.Path, filepath.FromSlash(segments[1])), i.Name, nil
+		}
+	}
+
+	return "", "", fmt.Errorf(
+		"task config file '%s' is in input '%s'; provide it locally with -i %s=PATH",
+		taskConfigPath,
+		artifact,
+		artifact,
+	)
+}
@@ -0,0 +1,37 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/concourse/go-concourse/concourse"
+)
+
+type JobTaskFlag struct {
+	PipelineName string
+	JobName      string
+	TaskName     string
+}
+
+func (jobTask *JobTaskFlag) UnmarshalFlag(value string) error {
+	vs := strings.SplitN(value, "/", 3)
+	if len(vs) != 3 {
+		return fmt.Errorf("invalid job task '%s' (must be pipeline/job/task)", value)
+	}
+
+	if vs[0] == "" {
+		return concourse.NameRequiredError("pipeline")
+	}
+	if vs[1] == "" {
+		return concourse.NameRequiredError("job")
+	}
+	if vs[2] == "" {
+		return concourse.NameRequiredError("task")
+	}
+
+	jobTask.PipelineName = vs[0]
+	jobTask.JobName = vs[1]
+	jobTask.TaskName = vs[2]
+
+	return nil
+}
@@ -0,0 +1,130 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/concourse/atc"
+	"github.com/concourse/fly/config"
+	"github.com/concourse/fly/template"
+)
+
+func localPlanTasks(planPath string, templateVariables template.Variables) ([]atc.TaskPlan, error) {
+	plan, err := config.LoadPlanConfig(planPath, templateVariables)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(plan.Steps) == 0 {
+		return nil, errors.New("plan has no steps")
+	}
+
+	tasks := []atc.TaskPlan{}
+	names := map[string]bool{}
+
+	for _, step := range plan.Steps {
+		if step.Name == "" {
+			return nil, errors.New("every step in the plan must have a name")
+		}
+
+		if names[step.Name] {
+			return nil, fmt.Errorf("step '%s' is defined more than once", step.Name)
+		}
+
+		names[step.Name] = true
+
+		if step.File == "" && step.Config == nil {
+			return nil, fmt.Errorf("step '%s' has neither a config nor a file", step.Name)
+		}
+
+		var taskConfig atc.TaskConfig
+
+		if step.File != "" {
+			taskConfig, err = config.ReadTaskConfig(step.File, templateVariables)
+			if err != nil {
+				return nil, fmt.Errorf("step '%s': %s", step.Name, err)
+			}
+		}
+
+		if step.Config != nil {
+			taskConfig = taskConfig.Merge(*step.Config)
+		}
+
+		taskConfig = config.OverrideTaskParams(taskConfig, step.Params)
+		taskConfig = config.OverrideTaskConfig(taskConfig, nil)
+
+		tasks = append(tasks, atc.TaskPlan{
+			Name:         step.Name,
+			Privileged:   step.Privileged,
+			Config:       &taskConfig,
+			InputMapping: step.InputMapping,
+		})
+	}
+
+	return tasks, nil
+}
+
+// planInputs determines the inputs that must be provided to the build, i.e.
+// those that are not produced as outputs by an earlier task.
+func planInputs(tasks []atc.TaskPlan) []atc.TaskInputConfig {
+	inputs := []atc.TaskInputConfig{}
+
+	required := map[string]bool{}
+	produced := map[string]bool{}
+
+	for _, task := range tasks {
+		for _, input := range task.Config.Inputs {
+			name := input.Name
+			if mapped, found := task.InputMapping[name]; found {
+				name = mapped
+			}
+
+			if produced[name] || required[name] {
+				continue
+			}
+
+			required[name] = true
+			inputs = append(inputs, atc.TaskInputConfig{Name: name})
+		}
+
+		for _, output := range task.Config.Outputs {
+			produced[output.Name] = true
+		}
+	}
+
+	return inputs
+}
+
+func planOutputs(tasks []atc.TaskPlan) []atc.TaskOutputConfig {
+	outputs := []atc.TaskOutputConfig{}
+
+	for _, task := range tasks {
+		outputs = append(outputs, task.Config.Outputs...)
+	}
+
+	return outputs
+}
+
+func warnUnknownParams(params template.Variables, tasks []atc.TaskPlan) {
+	names := []string{}
+	for name := range params {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		defined := false
+		for _, task := range tasks {
+			if _, found := task.Config.Params[name]; found {
+				defined = true
+			}
+		}
+
+		if !defined {
+			fmt.Fprintf(os.Stderr, "warning: param '%s' is not defined in the task config\n", name)
+		}
+	}
+}
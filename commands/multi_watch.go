@@ -0,0 +1,196 @@
+package commands
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/concourse/atc"
+	"github.com/concourse/fly/ui"
+	"github.com/concourse/go-concourse/concourse"
+	"github.com/fatih/color"
+)
+
+// the colors that each build's tag cycles through, leaving out red and green
+// so that the tags are not mistaken for a status
+var tagColors = []color.Attribute{
+	color.FgCyan,
+	color.FgMagenta,
+	color.FgYellow,
+	color.FgBlue,
+	color.FgHiCyan,
+	color.FgHiMagenta,
+	color.FgHiYellow,
+	color.FgHiBlue,
+}
+
+type watchTarget struct {
+	Job   JobFlag
+	Build string
+}
+
+type watchedBuild struct {
+	Tag      string
+	Build    atc.Build
+	Status   atc.BuildStatus
+	ExitCode int
+}
+
+// watchTargets pairs up the jobs and builds to watch. A single job applies to
+// every build, otherwise each build belongs to the job given in the same
+// position. The flags are gathered separately, so how they are interleaved on
+// the command line makes no difference.
+func watchTargets(jobs []JobFlag, builds []string) ([]watchTarget, error) {
+	targets := []watchTarget{}
+
+	switch {
+	case len(jobs) == 0 && len(builds) == 0:
+		targets = append(targets, watchTarget{})
+
+	case len(jobs) == 0:
+		for _, build := range builds {
+			targets = append(targets, watchTarget{Build: build})
+		}
+
+	case len(builds) == 0:
+		for _, job := range jobs {
+			targets = append(targets, watchTarget{Job: job})
+		}
+
+	case len(jobs) == 1:
+		for _, build := range builds {
+			targets = append(targets, watchTarget{Job: jobs[0], Build: build})
+		}
+
+	case len(jobs) == len(builds):
+		for i, job := range jobs {
+			targets = append(targets, watchTarget{Job: job, Build: builds[i]})
+		}
+
+	default:
+		return nil, fmt.Errorf("cannot pair %d builds with %d jobs (give one --job for all of the builds, or one per build)", len(builds), len(jobs))
+	}
+
+	return targets, nil
+}
+
+func buildTag(target watchTarget, build atc.Build) string {
+	switch {
+	case target.Job.JobName != "":
+		return fmt.Sprintf("%s/%s#%s", target.Job.PipelineName, target.Job.JobName, build.Name)
+	case build.JobName != "":
+		return fmt.Sprintf("%s/%s#%s", build.PipelineName, build.JobName, build.Name)
+	default:
+		return fmt.Sprintf("one-off#%d", build.ID)
+	}
+}
+
+// watchBuilds streams the events of every build at once, prefixing each line
+// with the build's tag, and returns how each of them finished.
+func (command *WatchCommand) watchBuilds(client concourse.Client, hook finishHook, watched []watchedBuild, stdout io.Writer) []watchedBuild {
+	width := 0
+	for _, w := range watched {
+		if len(w.Tag) > width {
+			width = len(w.Tag)
+		}
+	}
+
+	lock := new(sync.Mutex)
+	wg := new(sync.WaitGroup)
+
+	for i := range watched {
+		w := &watched[i]
+
+		prefix := color.New(tagColors[i%len(tagColors)]).Sprintf("%-*s |", width, w.Tag)
+		dst := &prefixWriter{dst: stdout, prefix: prefix + " ", lock: lock}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			w.ExitCode, w.Status = command.watchBuild(client, w.Build, dst)
+
+			dst.Flush()
+
+			hook.run(w.Build, w.Status)
+		}()
+	}
+
+	wg.Wait()
+
+	return watched
+}
+
+func printWatchedBuilds(dst io.Writer, watched []watchedBuild) error {
+	table := ui.Table{
+		Headers: ui.TableRow{
+			{Contents: "build", Color: color.New(color.Bold)},
+			{Contents: "status", Color: color.New(color.Bold)},
+		},
+	}
+
+	for _, w := range watched {
+		status := string(w.Status)
+		if status == "" {
+			status = "unknown"
+		}
+
+		statusColor := color.New(color.FgRed)
+		if w.Status == atc.StatusSucceeded {
+			statusColor = color.New(color.FgGreen)
+		}
+
+		table.Data = append(table.Data, ui.TableRow{
+			{Contents: w.Tag},
+			{Contents: status, Color: statusColor},
+		})
+	}
+
+	return table.Render(dst)
+}
+
+// prefixWriter writes whole lines to dst, each starting with the prefix.
+// Writers for several builds share a lock so that their lines never mix.
+type prefixWriter struct {
+	dst    io.Writer
+	prefix string
+	lock   *sync.Mutex
+
+	partial []byte
+}
+
+func (writer *prefixWriter) Write(p []byte) (int, error) {
+	writer.partial = append(writer.partial, p...)
+
+	lines := bytes.SplitAfter(writer.partial, []byte("\n"))
+
+	complete := lines[:len(lines)-1]
+	writer.partial = append([]byte{}, lines[len(lines)-1]...)
+
+	if len(complete) == 0 {
+		return len(p), nil
+	}
+
+	writer.lock.Lock()
+	defer writer.lock.Unlock()
+
+	for _, line := range complete {
+		_, err := fmt.Fprintf(writer.dst, "%s%s", writer.prefix, line)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return len(p), nil
+}
+
+// Flush writes out the last line if it was never terminated.
+func (writer *prefixWriter) Flush() error {
+	if len(writer.partial) == 0 {
+		return nil
+	}
+
+	_, err := writer.Write([]byte("\n"))
+	return err
+}
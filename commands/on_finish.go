@@ -0,0 +1,77 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+
+	"github.com/concourse/atc"
+	"github.com/concourse/go-concourse/concourse"
+)
+
+// finishHook runs the command given by --on-finish once a build has
+// finished, with the details of the build in its environment:
+//
+//	FLY_BUILD_ID        the build's ID
+//	FLY_BUILD_NAME      the build's name, e.g. its number within its job
+//	FLY_BUILD_PIPELINE  the pipeline the build is in, if any
+//	FLY_BUILD_JOB       the job the build is of, if any
+//	FLY_BUILD_STATUS    the status the build finished with, or unknown
+//	FLY_BUILD_DURATION  how many seconds the build ran for, if known
+//	FLY_BUILD_URL       where to view the build
+type finishHook struct {
+	command string
+	client  concourse.Client
+	atcURL  string
+}
+
+func (hook finishHook) run(build atc.Build, status atc.BuildStatus) {
+	if hook.command == "" {
+		return
+	}
+
+	if status == "" {
+		status = "unknown"
+	}
+
+	duration := ""
+
+	// the build as it was when watching started does not know when it ended
+	finished, found, err := hook.client.Build(strconv.Itoa(build.ID))
+	if err == nil && found {
+		build = finished
+
+		if build.StartTime != 0 && build.EndTime != 0 {
+			duration = strconv.FormatInt(build.EndTime-build.StartTime, 10)
+		}
+	}
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/C", hook.command)
+	} else {
+		cmd = exec.Command("sh", "-c", hook.command)
+	}
+
+	cmd.Env = append(
+		os.Environ(),
+		"FLY_BUILD_ID="+strconv.Itoa(build.ID),
+		"FLY_BUILD_NAME="+build.Name,
+		"FLY_BUILD_PIPELINE="+build.PipelineName,
+		"FLY_BUILD_JOB="+build.JobName,
+		"FLY_BUILD_STATUS="+string(status),
+		"FLY_BUILD_DURATION="+duration,
+		fmt.Sprintf("FLY_BUILD_URL=%s/builds/%d", hook.atcURL, build.ID),
+	)
+
+	// stdout is left to the build's output, which may be being consumed
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+
+	err = cmd.Run()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "--on-finish command failed:", err)
+	}
+}
@@ -5,9 +5,18 @@ import (
 	"strings"
 )
 
+type OutputForm int
+
+const (
+	OutputDirectory OutputForm = iota
+	OutputArchive
+	OutputStdout
+)
+
 type OutputPairFlag struct {
 	Name string
 	Path string
+	Form OutputForm
 }
 
 func (pair *OutputPairFlag) UnmarshalFlag(value string) error {
@@ -19,5 +28,14 @@ func (pair *OutputPairFlag) UnmarshalFlag(value string) error {
 	pair.Name = vs[0]
 	pair.Path = vs[1]
 
+	switch {
+	case pair.Path == "-":
+		pair.Form = OutputStdout
+	case strings.HasSuffix(pair.Path, ".tgz"), strings.HasSuffix(pair.Path, ".tar.gz"):
+		pair.Form = OutputArchive
+	default:
+		pair.Form = OutputDirectory
+	}
+
 	return nil
 }
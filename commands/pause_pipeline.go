@@ -2,7 +2,6 @@ package commands
 
 import (
 	"fmt"
-	"log"
 
 	"github.com/concourse/fly/rc"
 	"github.com/concourse/go-concourse/concourse"
@@ -17,7 +16,7 @@ func (command *PausePipelineCommand) Execute(args []string) error {
 
 	connection, err := rc.TargetConnection(Fly.Target)
 	if err != nil {
-		log.Fatalln(err)
+		fail(err)
 		return nil
 	}
 	client := concourse.NewClient(connection)
@@ -1,7 +1,6 @@
 package commands
 
 import (
-	"log"
 	"os"
 
 	"github.com/concourse/fly/rc"
@@ -15,7 +14,7 @@ type PipelinesCommand struct{}
 func (command *PipelinesCommand) Execute([]string) error {
 	connection, err := rc.TargetConnection(Fly.Target)
 	if err != nil {
-		log.Fatalln(err)
+		fail(err)
 		return nil
 	}
 
@@ -23,7 +22,7 @@ func (command *PipelinesCommand) Execute([]string) error {
 
 	pipelines, err := client.ListPipelines()
 	if err != nil {
-		log.Fatalln(err)
+		fail(err)
 	}
 
 	table := ui.Table{
@@ -0,0 +1,207 @@
+package commands
+
+import (
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/concourse/atc"
+	"github.com/concourse/atc/event"
+	"github.com/concourse/fly/template"
+	"github.com/concourse/go-concourse/concourse"
+)
+
+// what a secret is replaced with wherever it appears in a build's output
+const redactedSecret = "[redacted]"
+
+// variables with names like these are always treated as secret
+var defaultSecretPatterns = []string{
+	"*password*",
+	"*passwd*",
+	"*secret*",
+	"*token*",
+	"*private_key*",
+	"*access_key*",
+	"*api_key*",
+}
+
+// values shorter than this are not redacted, as they would match all over
+// the output and make it unreadable
+const minSecretLength = 3
+
+// redactSecrets marks the values of the given variables that are secret, i.e.
+// those named by --secret-var or matching one of the default patterns, to be
+// redacted from every build that is rendered afterwards.
+func (flags *RenderFlags) redactSecrets(variables template.Variables) {
+	for name, value := range variables {
+		if len(value) < minSecretLength || !flags.isSecret(name) {
+			continue
+		}
+
+		if !containsString(flags.secrets, value) {
+			flags.secrets = append(flags.secrets, value)
+		}
+	}
+
+	// replace the longest values first, so that a secret containing another
+	// is not left partially visible
+	sort.Sort(byLength(flags.secrets))
+}
+
+func (flags RenderFlags) isSecret(name string) bool {
+	name = strings.ToLower(name)
+
+	for _, pattern := range append(defaultSecretPatterns, flags.SecretVars...) {
+		matched, err := path.Match(strings.ToLower(pattern), name)
+		if err == nil && matched {
+			return true
+		}
+	}
+
+	return false
+}
+
+// redactedEvents masks secrets in the output and task configs of a build.
+// A step's output arrives in chunks that may split a secret in two, so the end
+// of each chunk that could be the start of a secret is held back and redacted
+// along with the step's next chunk. Whatever is still held is let through
+// before any other event and when the stream ends.
+type redactedEvents struct {
+	concourse.Events
+
+	secrets  []string
+	longest  int
+	replacer *strings.Replacer
+
+	held  map[string]event.Log
+	ready []atc.Event
+	err   error
+}
+
+func newRedactedEvents(events concourse.Events, secrets []string) *redactedEvents {
+	pairs := []string{}
+	longest := 0
+	for _, secret := range secrets {
+		pairs = append(pairs, secret, redactedSecret)
+
+		if len(secret) > longest {
+			longest = len(secret)
+		}
+	}
+
+	return &redactedEvents{
+		Events:   events,
+		secrets:  secrets,
+		longest:  longest,
+		replacer: strings.NewReplacer(pairs...),
+		held:     map[string]event.Log{},
+	}
+}
+
+func (events *redactedEvents) NextEvent() (atc.Event, error) {
+	for len(events.ready) == 0 {
+		if events.err != nil {
+			return nil, events.err
+		}
+
+		e, err := events.Events.NextEvent()
+		if err != nil {
+			events.err = err
+			events.release()
+			continue
+		}
+
+		events.redact(e)
+	}
+
+	e := events.ready[0]
+	events.ready = events.ready[1:]
+
+	return e, nil
+}
+
+func (events *redactedEvents) redact(e atc.Event) {
+	switch ev := e.(type) {
+	case event.Log:
+		key := string(ev.Origin.Type) + "/" + ev.Origin.Name
+		text := events.replacer.Replace(events.held[key].Payload + ev.Payload)
+
+		split := len(text) - events.secretStart(text)
+
+		delete(events.held, key)
+		if split < len(text) {
+			events.held[key] = event.Log{Time: ev.Time, Origin: ev.Origin, Payload: text[split:]}
+		}
+
+		if split > 0 {
+			ev.Payload = text[:split]
+			events.ready = append(events.ready, ev)
+		}
+
+		return
+
+	case event.Error:
+		ev.Message = events.replacer.Replace(ev.Message)
+		e = ev
+
+	case event.InitializeTask:
+		ev.TaskConfig.Params = events.redactParams(ev.TaskConfig.Params)
+		e = ev
+	}
+
+	events.release()
+	events.ready = append(events.ready, e)
+}
+
+// secretStart returns the length of the longest end of the text that is the
+// start of a secret, which may be completed by the next chunk.
+func (events *redactedEvents) secretStart(text string) int {
+	for length := events.longest - 1; length > 0; length-- {
+		if length > len(text) {
+			continue
+		}
+
+		for _, secret := range events.secrets {
+			if strings.HasPrefix(secret, text[len(text)-length:]) {
+				return length
+			}
+		}
+	}
+
+	return 0
+}
+
+// release lets through the output held back for every step.
+func (events *redactedEvents) release() {
+	keys := []string{}
+	for key := range events.held {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		events.ready = append(events.ready, events.held[key])
+	}
+
+	events.held = map[string]event.Log{}
+}
+
+func (events *redactedEvents) redactParams(params map[string]string) map[string]string {
+	if params == nil {
+		return nil
+	}
+
+	redacted := map[string]string{}
+	for name, value := range params {
+		redacted[name] = events.replacer.Replace(value)
+	}
+
+	return redacted
+}
+
+type byLength []string
+
+func (ss byLength) Len() int               { return len(ss) }
+func (ss byLength) Swap(i int, j int)      { ss[i], ss[j] = ss[j], ss[i] }
+func (ss byLength) Less(i int, j int) bool { return len(ss[i]) > len(ss[j]) }
@@ -0,0 +1,180 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/concourse/atc"
+	"github.com/concourse/atc/event"
+	"github.com/concourse/go-concourse/concourse"
+	"github.com/concourse/go-concourse/concourse/eventstream"
+	"github.com/fatih/color"
+)
+
+// RenderFlags configure how the events of a build are shown by the commands
+// that stream them.
+type RenderFlags struct {
+	OutputFormat  string   `long:"output-format"                    default:"text" choice:"text" choice:"json" description:"Show the build's events as text, or as JSON with one event per line"`
+	Steps         []string `long:"step"           value-name:"NAME" description:"Only show the output of the given step (can be specified multiple times)"`
+	HideResources bool     `long:"hide-resources"                   description:"Show a single status line for each get and put step instead of its output"`
+	Quiet         bool     `long:"quiet"                            description:"Only show the status that the build finished with"`
+	LogFile       string   `long:"log-file"       value-name:"PATH" description:"Also save the build's output to the given file, without colors"`
+	LogFormat     string   `long:"log-format"                       default:"text" choice:"text" choice:"json" description:"Save the build's output to the log file as text, or as JSON with one event per line"`
+	SecretVars    []string `long:"secret-var"     value-name:"NAME" description:"A variable whose value should be redacted from the output, in addition to those named like *password*, *token* or *secret* (can be a pattern, and can be specified multiple times)"`
+	Timestamps    string   `long:"timestamps"                       optional:"yes" optional-value:"absolute" choice:"absolute" choice:"relative" description:"Prefix each line of text output with the time it was logged, or the time since the build started, and summarize each step's duration at the end"`
+
+	log     *buildLog
+	secrets []string
+}
+
+// openLog creates the file given by --log-file, if any, for the output of
+// every build that is rendered afterwards.
+func (flags *RenderFlags) openLog() error {
+	if flags.LogFile == "" {
+		return nil
+	}
+
+	log, err := createBuildLog(flags.LogFile)
+	if err != nil {
+		return err
+	}
+
+	flags.log = log
+
+	return nil
+}
+
+// logged returns a writer that also saves what is written to the log, when
+// it is text. JSON logs are written by render as the events are read instead.
+func (flags RenderFlags) logged(dst io.Writer) io.Writer {
+	if flags.log == nil || flags.LogFormat == "json" {
+		return dst
+	}
+
+	return io.MultiWriter(dst, &ansiStrippingWriter{dst: flags.log})
+}
+
+// notices are written to stderr when the events are JSON, so that they can
+// be consumed as-is
+func (flags RenderFlags) noticeWriter(dst io.Writer) io.Writer {
+	if flags.OutputFormat == "json" {
+		return os.Stderr
+	}
+
+	return dst
+}
+
+// render writes the events of a build to dst, returning the exit code and
+// status that the build finished with. With stepHeaders, the name of each task
+// is printed before its output.
+func (flags RenderFlags) render(dst io.Writer, events concourse.Events, stepHeaders bool) (int, atc.BuildStatus) {
+	if len(flags.secrets) > 0 {
+		events = newRedactedEvents(events, flags.secrets)
+	}
+
+	if flags.log != nil && flags.LogFormat == "json" {
+		events = &loggedEvents{Events: events, log: flags.log}
+	}
+
+	statusSource := &statusEvents{Events: events}
+
+	var exitCode int
+	switch {
+	case flags.OutputFormat == "json":
+		exitCode = renderJSON(dst, flags.filter(statusSource, nil))
+
+	case flags.Quiet:
+		exitCode = eventstream.Render(ioutil.Discard, statusSource)
+
+		printStatus(dst, statusSource.status)
+
+	default:
+		var source concourse.Events = statusSource
+
+		var timestamps *timestampEvents
+		if flags.Timestamps != "" {
+			timestamps = &timestampEvents{
+				Events:   source,
+				dst:      &timestampWriter{dst: dst},
+				relative: flags.Timestamps == "relative",
+			}
+
+			source = timestamps
+			dst = timestamps.dst
+		}
+
+		source = flags.filter(source, dst)
+
+		if stepHeaders {
+			source = &stepHeaderEvents{Events: source, dst: dst}
+		}
+
+		exitCode = eventstream.Render(dst, source)
+
+		if timestamps != nil {
+			timestamps.printSummary(timestamps.dst.dst)
+		}
+	}
+
+	return buildExitCode(statusSource.status, exitCode), statusSource.status
+}
+
+// filter leaves out the events that the flags ask not to be shown. For text
+// output, dst is given so that hidden resources can be summarized.
+func (flags RenderFlags) filter(events concourse.Events, dst io.Writer) concourse.Events {
+	if len(flags.Steps) == 0 && !flags.HideResources && !flags.Quiet {
+		return events
+	}
+
+	return &filteredEvents{
+		Events:        events,
+		steps:         flags.Steps,
+		hideResources: flags.HideResources,
+		statusOnly:    flags.Quiet,
+		dst:           dst,
+	}
+}
+
+func printStatus(dst io.Writer, status atc.BuildStatus) {
+	switch status {
+	case "":
+		fmt.Fprintln(dst, "unknown")
+	case atc.StatusSucceeded:
+		fmt.Fprintln(dst, color.GreenString(string(status)))
+	case atc.StatusFailed:
+		fmt.Fprintln(dst, color.RedString(string(status)))
+	case atc.StatusErrored:
+		fmt.Fprintln(dst, color.MagentaString(string(status)))
+	case atc.StatusAborted:
+		fmt.Fprintln(dst, color.YellowString(string(status)))
+	default:
+		fmt.Fprintln(dst, status)
+	}
+}
+
+// renderJSON writes each event in the same envelope that the ATC streams it
+// in, e.g. {"event":"log","version":"...","data":{...}}.
+func renderJSON(dst io.Writer, events concourse.Events) int {
+	encoder := json.NewEncoder(dst)
+
+	for {
+		e, err := events.NextEvent()
+		if err == io.EOF {
+			return ExitSucceeded
+		}
+
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "failed to read next event:", err)
+			return ExitClientError
+		}
+
+		err = encoder.Encode(event.Message{Event: e})
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "failed to encode event:", err)
+			return ExitClientError
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package commands
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/concourse/atc"
+	"github.com/concourse/go-concourse/concourse"
+)
+
+// how many versions to look through at a time for a pinned version
+const resourceVersionsPageSize = 100
+
+// resourceVersion finds the version of a resource to get an input at: its
+// latest version, or the one that has all of the pinned fields.
+func resourceVersion(client concourse.Client, pipelineName string, resourceName string, pinned atc.Version) (atc.Version, error) {
+	page := &concourse.Page{Limit: resourceVersionsPageSize}
+	if len(pinned) == 0 {
+		page.Limit = 1
+	}
+
+	for page != nil {
+		versions, pagination, found, err := client.ResourceVersions(pipelineName, resourceName, *page)
+		if err != nil {
+			return nil, err
+		}
+
+		if !found {
+			return nil, fmt.Errorf("resource '%s' not found in pipeline '%s'", resourceName, pipelineName)
+		}
+
+		for _, version := range versions {
+			if versionHas(version.Version, pinned) {
+				return version.Version, nil
+			}
+		}
+
+		if len(pinned) == 0 {
+			return nil, fmt.Errorf("resource '%s' in pipeline '%s' has no versions yet", resourceName, pipelineName)
+		}
+
+		page = pagination.Next
+	}
+
+	return nil, fmt.Errorf("version %s of resource '%s' not found in pipeline '%s'", formatVersion(pinned), resourceName, pipelineName)
+}
+
+func versionHas(version atc.Version, fields atc.Version) bool {
+	for key, value := range fields {
+		if version[key] != value {
+			return false
+		}
+	}
+
+	return true
+}
+
+func formatVersion(version atc.Version) string {
+	pairs := []string{}
+	for key, value := range version {
+		pairs = append(pairs, key+":"+value)
+	}
+
+	sort.Strings(pairs)
+
+	return strings.Join(pairs, ",")
+}
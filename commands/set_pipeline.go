@@ -1,8 +1,6 @@
 package commands
 
 import (
-	"log"
-
 	"github.com/concourse/atc/web"
 	"github.com/concourse/fly/rc"
 	"github.com/concourse/fly/template"
@@ -29,7 +27,7 @@ func (command *SetPipelineCommand) Execute(args []string) error {
 
 	connection, err := rc.TargetConnection(Fly.Target)
 	if err != nil {
-		log.Fatalln(err)
+		fail(err)
 		return nil
 	}
 	client := concourse.NewClient(connection)
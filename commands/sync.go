@@ -2,7 +2,6 @@ package commands
 
 import (
 	"fmt"
-	"log"
 	"runtime"
 
 	"github.com/inconshreveable/go-update"
@@ -16,14 +15,14 @@ type SyncCommand struct{}
 func (command *SyncCommand) Execute(args []string) error {
 	connection, err := rc.TargetConnection(Fly.Target)
 	if err != nil {
-		log.Fatalln(err)
+		fail(err)
 		return nil
 	}
 
 	client := concourse.NewClient(connection)
 	body, err := client.GetCLIReader(runtime.GOARCH, runtime.GOOS)
 	if err != nil {
-		log.Fatalln(err)
+		fail(err)
 	}
 
 	fmt.Printf("downloading fly from %s... ", connection.URL())
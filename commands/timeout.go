@@ -0,0 +1,42 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/concourse/atc"
+	"github.com/concourse/go-concourse/concourse"
+)
+
+// abortAfterTimeout aborts the build once the timeout has elapsed. The
+// returned function stops the timer and reports whether it had already fired.
+func abortAfterTimeout(client concourse.Client, build atc.Build, timeout time.Duration) func() bool {
+	if timeout == 0 {
+		return func() bool { return false }
+	}
+
+	timer := time.AfterFunc(timeout, func() {
+		fmt.Fprintf(os.Stderr, "\ntimed out after %s; aborting...\n", timeout)
+
+		err := client.AbortBuild(strconv.Itoa(build.ID))
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "failed to abort:", err)
+		}
+	})
+
+	return func() bool {
+		return !timer.Stop()
+	}
+}
+
+// timeoutExitCode only reports the build as timed out if aborting it took
+// effect, as the timer can fire once the build has already finished.
+func timeoutExitCode(timedOut bool, status atc.BuildStatus, exitCode int) int {
+	if timedOut && status == atc.StatusAborted {
+		return ExitTimedOut
+	}
+
+	return exitCode
+}
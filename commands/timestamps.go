@@ -0,0 +1,209 @@
+package commands
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/concourse/atc"
+	"github.com/concourse/atc/event"
+	"github.com/concourse/fly/ui"
+	"github.com/concourse/go-concourse/concourse"
+	"github.com/fatih/color"
+)
+
+type stepTiming struct {
+	Origin event.Origin
+	Start  time.Time
+	End    time.Time
+	Status string
+}
+
+// timestampEvents stamps the lines written for each event with the time it
+// happened, and records when each step started and finished so that their
+// durations can be summarized once the build is over.
+type timestampEvents struct {
+	concourse.Events
+
+	dst      *timestampWriter
+	relative bool
+
+	start time.Time
+	last  time.Time
+	steps []*stepTiming
+}
+
+func (events *timestampEvents) NextEvent() (atc.Event, error) {
+	e, err := events.Events.NextEvent()
+	if err != nil {
+		return e, err
+	}
+
+	at := events.eventTime(e)
+	if events.start.IsZero() {
+		events.start = at
+	}
+
+	if events.relative {
+		events.dst.stamp = formatElapsed(at.Sub(events.start))
+	} else {
+		events.dst.stamp = at.Format("15:04:05")
+	}
+
+	origin, found := eventOrigin(e)
+	if !found || origin.Name == "" {
+		return e, nil
+	}
+
+	step := events.step(origin, at)
+	step.End = at
+
+	switch ev := e.(type) {
+	case event.StartTask:
+		step.Start = at
+	case event.FinishTask:
+		step.Status = exitStatusResult(ev.ExitStatus)
+	case event.FinishGet:
+		step.Status = exitStatusResult(ev.ExitStatus)
+	case event.FinishPut:
+		step.Status = exitStatusResult(ev.ExitStatus)
+	case event.Error:
+		step.Status = "errored"
+	}
+
+	return e, nil
+}
+
+func (events *timestampEvents) step(origin event.Origin, at time.Time) *stepTiming {
+	for _, step := range events.steps {
+		if step.Origin.Name == origin.Name && step.Origin.Type == origin.Type {
+			return step
+		}
+	}
+
+	step := &stepTiming{Origin: origin, Start: at}
+	events.steps = append(events.steps, step)
+
+	return step
+}
+
+func (events *timestampEvents) printSummary(dst io.Writer) error {
+	if len(events.steps) == 0 {
+		return nil
+	}
+
+	table := ui.Table{
+		Headers: ui.TableRow{
+			{Contents: "step", Color: color.New(color.Bold)},
+			{Contents: "type", Color: color.New(color.Bold)},
+			{Contents: "status", Color: color.New(color.Bold)},
+			{Contents: "duration", Color: color.New(color.Bold)},
+		},
+	}
+
+	for _, step := range events.steps {
+		status := step.Status
+		if status == "" {
+			status = "unfinished"
+		}
+
+		var statusColor *color.Color
+		switch status {
+		case "succeeded":
+			statusColor = color.New(color.FgGreen)
+		case "failed", "errored":
+			statusColor = color.New(color.FgRed)
+		}
+
+		table.Data = append(table.Data, ui.TableRow{
+			{Contents: step.Origin.Name},
+			{Contents: string(step.Origin.Type)},
+			{Contents: status, Color: statusColor},
+			{Contents: step.End.Sub(step.Start).String()},
+		})
+	}
+
+	fmt.Fprintln(dst)
+
+	return table.Render(dst)
+}
+
+// eventTime determines when an event happened. Events that the ATC does not
+// timestamp are taken to have happened at the same time as the last one that
+// it did, so that replaying a finished build does not mix in the current time.
+// Only before any time is known are they taken to happen as they are received.
+func (events *timestampEvents) eventTime(e atc.Event) time.Time {
+	var unix int64
+
+	switch ev := e.(type) {
+	case event.Log:
+		unix = ev.Time
+	case event.InitializeTask:
+		unix = ev.Time
+	case event.StartTask:
+		unix = ev.Time
+	case event.FinishTask:
+		unix = ev.Time
+	case event.Status:
+		unix = ev.Time
+	}
+
+	switch {
+	case unix != 0:
+		events.last = time.Unix(unix, 0)
+	case events.last.IsZero():
+		return time.Now().Truncate(time.Second)
+	}
+
+	return events.last
+}
+
+func exitStatusResult(exitStatus int) string {
+	if exitStatus == 0 {
+		return "succeeded"
+	}
+
+	return "failed"
+}
+
+func formatElapsed(elapsed time.Duration) string {
+	seconds := int(elapsed / time.Second)
+	return fmt.Sprintf("%02d:%02d:%02d", seconds/3600, seconds/60%60, seconds%60)
+}
+
+// timestampWriter writes the current stamp at the start of every line.
+type timestampWriter struct {
+	dst   io.Writer
+	stamp string
+
+	midLine bool
+}
+
+func (writer *timestampWriter) Write(p []byte) (int, error) {
+	remaining := p
+
+	for len(remaining) > 0 {
+		line := remaining
+		if i := bytes.IndexByte(remaining, '\n'); i != -1 {
+			line = remaining[:i+1]
+		}
+
+		if !writer.midLine {
+			_, err := fmt.Fprintf(writer.dst, "%s ", writer.stamp)
+			if err != nil {
+				return 0, err
+			}
+		}
+
+		_, err := writer.dst.Write(line)
+		if err != nil {
+			return 0, err
+		}
+
+		writer.midLine = line[len(line)-1] != '\n'
+		remaining = remaining[len(line):]
+	}
+
+	return len(p), nil
+}
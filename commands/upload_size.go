@@ -0,0 +1,127 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// uploadSize sums up the size of the files that would be uploaded from the
+// directory, along with the size of each file and directory within it.
+func uploadSize(dir string, excludeIgnored bool) (int64, map[string]int64, error) {
+	sizes := map[string]int64{}
+	total := int64(0)
+
+	record := func(relative string, size int64) {
+		total += size
+
+		for path := relative; path != "." && path != string(filepath.Separator); path = filepath.Dir(path) {
+			sizes[path] += size
+		}
+	}
+
+	if excludeIgnored {
+		files, err := getGitFiles(dir)
+		if err != nil {
+			return 0, nil, err
+		}
+
+		for _, file := range files {
+			info, err := os.Lstat(filepath.Join(dir, file))
+			if err != nil {
+				return 0, nil, err
+			}
+
+			if info.Mode().IsRegular() {
+				record(filepath.Clean(file), info.Size())
+			}
+		}
+
+		return total, sizes, nil
+	}
+
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		relative, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+
+		record(relative, info.Size())
+
+		return nil
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return total, sizes, nil
+}
+
+// checkUploadSizes refuses to upload any input directory larger than the
+// limit, listing its largest paths so that they can be ignored.
+func checkUploadSizes(dst io.Writer, inputMappings []InputPairFlag, limit int64, excludeIgnored bool) error {
+	for _, i := range inputMappings {
+		if i.Form != InputDirectory || i.Ref != "" || i.Resource.ResourceName != "" {
+			continue
+		}
+
+		total, sizes, err := uploadSize(i.Path, excludeIgnored)
+		if err != nil {
+			return err
+		}
+
+		if total <= limit {
+			continue
+		}
+
+		fmt.Fprintf(dst, "input `%s` is %s, which is over the upload limit of %s\n", i.Name, formatByteSize(total), formatByteSize(limit))
+		fmt.Fprintln(dst, "")
+		fmt.Fprintln(dst, "largest paths:")
+
+		paths := make([]string, 0, len(sizes))
+		for path := range sizes {
+			paths = append(paths, path)
+		}
+
+		sort.Sort(bySize{paths: paths, sizes: sizes})
+
+		if len(paths) > 10 {
+			paths = paths[:10]
+		}
+
+		for _, path := range paths {
+			fmt.Fprintf(dst, "  %8s  %s\n", formatByteSize(sizes[path]), filepath.ToSlash(path))
+		}
+
+		fmt.Fprintln(dst, "")
+
+		return fmt.Errorf("input `%s` is too large to upload (ignore paths with -x, raise --max-upload-size, or use --force)", i.Name)
+	}
+
+	return nil
+}
+
+type bySize struct {
+	paths []string
+	sizes map[string]int64
+}
+
+func (s bySize) Len() int          { return len(s.paths) }
+func (s bySize) Swap(i int, j int) { s.paths[i], s.paths[j] = s.paths[j], s.paths[i] }
+func (s bySize) Less(i int, j int) bool {
+	if s.sizes[s.paths[i]] == s.sizes[s.paths[j]] {
+		return s.paths[i] < s.paths[j]
+	}
+
+	return s.sizes[s.paths[i]] > s.sizes[s.paths[j]]
+}
@@ -0,0 +1,36 @@
+package commands
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/concourse/fly/config"
+)
+
+type ValidateTaskCommand struct {
+	TaskConfig PathFlag `short:"c" long:"config" required:"true" description:"The task config to validate"`
+}
+
+func (command *ValidateTaskCommand) Execute(args []string) error {
+	configPath := string(command.TaskConfig)
+
+	configFile, err := ioutil.ReadFile(configPath)
+	if err != nil {
+		failWithErrorf("could not open config file", err)
+	}
+
+	problems := config.ValidateTaskConfig(configFile)
+	if len(problems) == 0 {
+		fmt.Println("looks good")
+		return nil
+	}
+
+	for _, problem := range problems {
+		fmt.Fprintf(os.Stderr, "%s:%s\n", configPath, problem)
+	}
+
+	os.Exit(ExitFailed)
+
+	return nil
+}
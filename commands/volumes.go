@@ -2,7 +2,6 @@ package commands
 
 import (
 	"fmt"
-	"log"
 	"os"
 	"sort"
 	"strings"
@@ -20,14 +19,14 @@ type VolumesCommand struct{}
 func (command *VolumesCommand) Execute([]string) error {
 	connection, err := rc.TargetConnection(Fly.Target)
 	if err != nil {
-		log.Fatalln(err)
+		fail(err)
 	}
 
 	client := concourse.NewClient(connection)
 
 	volumes, err := client.ListVolumes()
 	if err != nil {
-		log.Fatalln(err)
+		fail(err)
 	}
 
 	table := ui.Table{
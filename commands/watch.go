@@ -1,46 +1,191 @@
 package commands
 
 import (
+	"errors"
 	"fmt"
-	"log"
+	"io"
 	"os"
+	"time"
 
+	"github.com/concourse/atc"
 	"github.com/concourse/fly/rc"
+	"github.com/concourse/fly/template"
 	"github.com/concourse/go-concourse/concourse"
-	"github.com/concourse/go-concourse/concourse/eventstream"
+	"github.com/fatih/color"
 )
 
+// how often to check for the next build of a job being followed
+const followPollInterval = 5 * time.Second
+
+// how many of a job's newer builds to list when looking for the next one
+const followPageSize = 100
+
 type WatchCommand struct {
-	Job   JobFlag `short:"j" long:"job"   value-name:"PIPELINE/JOB"   description:"Watches builds of the given job"`
-	Build string  `short:"b" long:"build"                               description:"Watches a specific build"`
+	Job      []JobFlag          `short:"j" long:"job"            value-name:"PIPELINE/JOB" description:"Watches builds of the given job (can be specified multiple times)"`
+	Build    []string           `short:"b" long:"build"                                    description:"Watches a specific build (can be specified multiple times). Without --job this is a build ID; with one --job it names a build of that job, and with several each names a build of the --job in the same position"`
+	Follow   bool               `long:"follow"                                             description:"Keep watching the job's builds as they start, until interrupted"`
+	Var      []VariablePairFlag `short:"v" long:"var"            value-name:"NAME=VALUE"   description:"A variable to redact from the output if it is secret (can be specified multiple times)"`
+	VarsFrom []PathFlag         `short:"l" long:"load-vars-from"                           description:"Variables to redact from the output if they are secret, e.g. the file given to set-pipeline"`
+	OnFinish string             `long:"on-finish"                value-name:"COMMAND"      description:"A command to run after each build finishes, with its details in FLY_BUILD_* environment variables"`
+	Timeout  time.Duration      `long:"timeout"                  value-name:"DURATION"     description:"Abort the build if it has not finished within the given duration (e.g. 30m)"`
+
+	RenderFlags
 }
 
 func (command *WatchCommand) Execute(args []string) error {
+	targets, err := watchTargets(command.Job, command.Build)
+	if err != nil {
+		return err
+	}
+
+	if command.Follow {
+		if targets[0].Job.JobName == "" {
+			return errors.New("--follow can only be used with --job")
+		}
+
+		if len(targets) > 1 {
+			return errors.New("--follow can only be used when watching a single job")
+		}
+	}
+
+	if len(targets) > 1 && command.OutputFormat == "json" {
+		return errors.New("--output-format json can only be used when watching a single build")
+	}
+
+	variables := template.Variables{}
+	for _, v := range command.Var {
+		variables[v.Name] = v.Value
+	}
+
+	variables, err = loadTemplateVariables(command.VarsFrom, variables)
+	if err != nil {
+		return err
+	}
+
+	command.redactSecrets(variables)
+
+	err = command.openLog()
+	if err != nil {
+		return err
+	}
+
+	stdout := command.logged(os.Stdout)
+
 	connection, err := rc.TargetConnection(Fly.Target)
 	if err != nil {
-		log.Fatalln(err)
+		fail(err)
 		return nil
 	}
 
 	client := concourse.NewClient(connection)
 
-	build, err := GetBuild(client, command.Job.JobName, command.Build, command.Job.PipelineName)
-	if err != nil {
-		log.Fatalln(err)
+	hook := finishHook{
+		command: command.OnFinish,
+		client:  client,
+		atcURL:  connection.URL(),
+	}
+
+	watched := []watchedBuild{}
+	for _, target := range targets {
+		build, err := GetBuild(client, target.Job.JobName, target.Build, target.Job.PipelineName)
+		if err != nil {
+			fail(err)
+		}
+
+		watched = append(watched, watchedBuild{
+			Tag:   buildTag(target, build),
+			Build: build,
+		})
+	}
+
+	if len(watched) > 1 {
+		watched = command.watchBuilds(client, hook, watched, stdout)
+
+		fmt.Fprintln(stdout)
+		printWatchedBuilds(stdout, watched)
+
+		exitCodes := []int{}
+		for _, w := range watched {
+			exitCodes = append(exitCodes, w.ExitCode)
+		}
+
+		os.Exit(worstExitCode(exitCodes...))
 	}
 
-	eventSource, err := client.BuildEvents(fmt.Sprintf("%d", build.ID))
+	build := watched[0].Build
+
+	for {
+		exitCode, status := command.watchBuild(client, build, stdout)
+
+		hook.run(build, status)
+
+		if !command.Follow {
+			os.Exit(exitCode)
+		}
+
+		if status == "" {
+			status = "unknown"
+		}
+
+		noticesTo := command.noticeWriter(stdout)
+		fmt.Fprintf(noticesTo, "%s\n", color.New(color.Bold).Sprintf("--- %s #%s %s ---", build.JobName, build.Name, status))
+
+		build, err = command.nextBuild(client, targets[0].Job, build, noticesTo)
+		if err != nil {
+			fail(err)
+		}
+	}
+}
 
+func (command *WatchCommand) watchBuild(client concourse.Client, build atc.Build, dst io.Writer) (int, atc.BuildStatus) {
+	eventSource, err := buildEvents(client, build)
 	if err != nil {
-		log.Println("failed to attach to stream:", err)
-		os.Exit(1)
+		failWithErrorf("failed to attach to stream", err)
 	}
 
-	exitCode := eventstream.Render(os.Stdout, eventSource)
+	stopTimeout := abortAfterTimeout(client, build, command.Timeout)
+
+	exitCode, status := command.render(dst, eventSource, false)
 
 	eventSource.Close()
 
-	os.Exit(exitCode)
+	exitCode = timeoutExitCode(stopTimeout(), status, exitCode)
+
+	return exitCode, status
+}
+
+// nextBuild waits for the job to have a build newer than the given one. If
+// several have started since, the oldest is watched first. Paging "until" a
+// build lists the ones newer than it.
+func (command *WatchCommand) nextBuild(client concourse.Client, jobFlag JobFlag, last atc.Build, noticesTo io.Writer) (atc.Build, error) {
+	waiting := false
 
-	return nil
+	for {
+		builds, _, found, err := client.JobBuilds(jobFlag.PipelineName, jobFlag.JobName, concourse.Page{Until: last.ID, Limit: followPageSize})
+		if err != nil {
+			return atc.Build{}, fmt.Errorf("failed to get builds of job %s", err)
+		}
+
+		if !found {
+			return atc.Build{}, errors.New("job not found")
+		}
+
+		var next *atc.Build
+		for i, candidate := range builds {
+			if candidate.ID > last.ID && (next == nil || candidate.ID < next.ID) {
+				next = &builds[i]
+			}
+		}
+
+		if next != nil {
+			return *next, nil
+		}
+
+		if !waiting {
+			fmt.Fprintln(noticesTo, "waiting for the next build...")
+			waiting = true
+		}
+
+		time.Sleep(followPollInterval)
+	}
 }
@@ -1,7 +1,6 @@
 package commands
 
 import (
-	"log"
 	"os"
 	"sort"
 	"strconv"
@@ -21,14 +20,14 @@ type WorkersCommand struct {
 func (command *WorkersCommand) Execute([]string) error {
 	connection, err := rc.TargetConnection(Fly.Target)
 	if err != nil {
-		log.Fatalln(err)
+		fail(err)
 	}
 
 	client := concourse.NewClient(connection)
 
 	workers, err := client.ListWorkers()
 	if err != nil {
-		log.Fatalln(err)
+		fail(err)
 	}
 
 	headers := ui.TableRow{
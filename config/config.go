@@ -1,27 +1,48 @@
 package config
 
 import (
+	"fmt"
 	"io/ioutil"
-	"log"
 	"syscall"
 
 	"github.com/concourse/atc"
+	"github.com/concourse/fly/template"
 	"gopkg.in/yaml.v2"
 )
 
-func LoadTaskConfig(configPath string, args []string) atc.TaskConfig {
+func LoadTaskConfig(configPath string, templateVariables template.Variables, args []string) (atc.TaskConfig, error) {
+	config, err := ReadTaskConfig(configPath, templateVariables)
+	if err != nil {
+		return atc.TaskConfig{}, err
+	}
+
+	return OverrideTaskConfig(config, args), nil
+}
+
+func ReadTaskConfig(configPath string, templateVariables template.Variables) (atc.TaskConfig, error) {
 	configFile, err := ioutil.ReadFile(configPath)
 	if err != nil {
-		log.Fatalln("could not open config file:", err)
+		return atc.TaskConfig{}, fmt.Errorf("could not open config file: %s", err)
+	}
+
+	// evaluated even without any variables, so that a placeholder that is left
+	// unbound is reported rather than sent to the ATC as-is
+	configFile, err = template.Evaluate(configFile, templateVariables)
+	if err != nil {
+		return atc.TaskConfig{}, fmt.Errorf("failed to evaluate variables into template: %s", err)
 	}
 
 	var config atc.TaskConfig
 
 	err = yaml.Unmarshal(configFile, &config)
 	if err != nil {
-		log.Fatalln("could not parse config file:", err)
+		return atc.TaskConfig{}, fmt.Errorf("could not parse config file: %s", err)
 	}
 
+	return config, nil
+}
+
+func OverrideTaskConfig(config atc.TaskConfig, args []string) atc.TaskConfig {
 	config.Run.Args = append(config.Run.Args, args...)
 
 	for k, _ := range config.Params {
@@ -33,3 +54,15 @@ func LoadTaskConfig(configPath string, args []string) atc.TaskConfig {
 
 	return config
 }
+
+func OverrideTaskParams(config atc.TaskConfig, params map[string]string) atc.TaskConfig {
+	if len(params) > 0 && config.Params == nil {
+		config.Params = map[string]string{}
+	}
+
+	for k, v := range params {
+		config.Params[k] = v
+	}
+
+	return config
+}
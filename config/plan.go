@@ -0,0 +1,56 @@
+package config
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/concourse/atc"
+	"github.com/concourse/fly/template"
+	"gopkg.in/yaml.v2"
+)
+
+type PlanConfig struct {
+	Steps []StepConfig `yaml:"steps"`
+}
+
+type StepConfig struct {
+	Name string `yaml:"name"`
+
+	File   string          `yaml:"file,omitempty"`
+	Config *atc.TaskConfig `yaml:"config,omitempty"`
+
+	Privileged   bool              `yaml:"privileged,omitempty"`
+	Params       map[string]string `yaml:"params,omitempty"`
+	InputMapping map[string]string `yaml:"input_mapping,omitempty"`
+}
+
+func LoadPlanConfig(planPath string, templateVariables template.Variables) (PlanConfig, error) {
+	planFile, err := ioutil.ReadFile(planPath)
+	if err != nil {
+		return PlanConfig{}, fmt.Errorf("could not open plan file: %s", err)
+	}
+
+	// evaluated even without any variables, so that a placeholder that is left
+	// unbound is reported rather than sent to the ATC as-is
+	planFile, err = template.Evaluate(planFile, templateVariables)
+	if err != nil {
+		return PlanConfig{}, fmt.Errorf("failed to evaluate variables into template: %s", err)
+	}
+
+	var plan PlanConfig
+
+	err = yaml.Unmarshal(planFile, &plan)
+	if err != nil {
+		return PlanConfig{}, fmt.Errorf("could not parse plan file: %s", err)
+	}
+
+	// task config files are relative to the plan that refers to them
+	for i, step := range plan.Steps {
+		if step.File != "" && !filepath.IsAbs(step.File) {
+			plan.Steps[i].File = filepath.Join(filepath.Dir(planPath), step.File)
+		}
+	}
+
+	return plan, nil
+}
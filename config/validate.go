@@ -0,0 +1,267 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/concourse/atc"
+	"gopkg.in/yaml.v2"
+)
+
+type TaskConfigProblem struct {
+	Line    int
+	Column  int
+	Message string
+}
+
+func (problem TaskConfigProblem) String() string {
+	switch {
+	case problem.Line == 0:
+		return problem.Message
+	case problem.Column == 0:
+		return fmt.Sprintf("%d: %s", problem.Line, problem.Message)
+	default:
+		return fmt.Sprintf("%d:%d: %s", problem.Line, problem.Column, problem.Message)
+	}
+}
+
+var yamlLineRegex = regexp.MustCompile(`^(?:yaml: )?line (\d+): (.*)$`)
+
+func ValidateTaskConfig(configFile []byte) []TaskConfigProblem {
+	var raw yaml.MapSlice
+	err := yaml.Unmarshal(configFile, &raw)
+	if err != nil {
+		return []TaskConfigProblem{yamlProblem(strings.Split(string(configFile), "\n"), err.Error())}
+	}
+
+	lines := strings.Split(string(configFile), "\n")
+
+	problems := unknownKeys(lines, raw, reflect.TypeOf(atc.TaskConfig{}), nil)
+
+	var config atc.TaskConfig
+	err = yaml.Unmarshal(configFile, &config)
+	if typeErr, ok := err.(*yaml.TypeError); ok {
+		for _, message := range typeErr.Errors {
+			problems = append(problems, yamlProblem(lines, message))
+		}
+	} else if err != nil {
+		problems = append(problems, yamlProblem(lines, err.Error()))
+	}
+
+	problems = append(problems, collidingInputs(lines, config.Inputs)...)
+
+	sort.Stable(problemsByPosition(problems))
+
+	if err := config.Validate(); err != nil {
+		problems = append(problems, TaskConfigProblem{Message: err.Error()})
+	}
+
+	return problems
+}
+
+// yamlProblem turns an error from the YAML parser into a problem. The parser
+// only reports the line, so the column is that of the value the line sets
+// for type errors, and otherwise of the first token on the line, where
+// syntax errors such as stray tabs are found.
+func yamlProblem(lines []string, message string) TaskConfigProblem {
+	match := yamlLineRegex.FindStringSubmatch(message)
+	if match == nil {
+		return TaskConfigProblem{Message: message}
+	}
+
+	line, _ := strconv.Atoi(match[1])
+
+	column := 0
+	if line > 0 && line <= len(lines) {
+		column = yamlColumn(lines[line-1], strings.Contains(match[2], "cannot unmarshal"))
+	}
+
+	return TaskConfigProblem{Line: line, Column: column, Message: match[2]}
+}
+
+var yamlValueRegex = regexp.MustCompile(`^(\s*(?:-\s+)?[^\s:#][^:#]*:\s+)\S`)
+
+func yamlColumn(line string, value bool) int {
+	if value {
+		if match := yamlValueRegex.FindStringSubmatch(line); match != nil {
+			return len(match[1]) + 1
+		}
+	}
+
+	trimmed := strings.TrimLeft(line, " ")
+	if trimmed == "" {
+		return 0
+	}
+
+	return len(line) - len(trimmed) + 1
+}
+
+func unknownKeys(lines []string, value interface{}, configType reflect.Type, path []string) []TaskConfigProblem {
+	for configType.Kind() == reflect.Ptr {
+		configType = configType.Elem()
+	}
+
+	problems := []TaskConfigProblem{}
+
+	switch configType.Kind() {
+	case reflect.Struct:
+		mapping, ok := value.(yaml.MapSlice)
+		if !ok {
+			return problems
+		}
+
+		fields := yamlFields(configType)
+
+		for _, item := range mapping {
+			key := fmt.Sprintf("%v", item.Key)
+			keyPath := append(append([]string{}, path...), key)
+
+			fieldType, found := fields[key]
+			if !found {
+				line, column := locateKey(lines, keyPath)
+				problems = append(problems, TaskConfigProblem{
+					Line:    line,
+					Column:  column,
+					Message: fmt.Sprintf("unknown key '%s'", strings.Join(keyPath, ".")),
+				})
+				continue
+			}
+
+			problems = append(problems, unknownKeys(lines, item.Value, fieldType, keyPath)...)
+		}
+
+	case reflect.Slice:
+		sequence, ok := value.([]interface{})
+		if !ok {
+			return problems
+		}
+
+		for _, element := range sequence {
+			problems = append(problems, unknownKeys(lines, element, configType.Elem(), path)...)
+		}
+	}
+
+	return problems
+}
+
+func yamlFields(configType reflect.Type) map[string]reflect.Type {
+	fields := map[string]reflect.Type{}
+
+	for i := 0; i < configType.NumField(); i++ {
+		field := configType.Field(i)
+
+		tag := strings.Split(field.Tag.Get("yaml"), ",")
+
+		name := tag[0]
+		if name == "-" {
+			continue
+		}
+
+		if len(tag) > 1 && tag[1] == "inline" {
+			for inlineName, inlineType := range yamlFields(field.Type) {
+				fields[inlineName] = inlineType
+			}
+			continue
+		}
+
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+
+		fields[name] = field.Type
+	}
+
+	return fields
+}
+
+func collidingInputs(lines []string, inputs []atc.TaskInputConfig) []TaskConfigProblem {
+	problems := []TaskConfigProblem{}
+
+	names := map[string]bool{}
+	paths := map[string]string{}
+
+	for _, input := range inputs {
+		if names[input.Name] {
+			line, column := locateValue(lines, "name", input.Name)
+			problems = append(problems, TaskConfigProblem{
+				Line:    line,
+				Column:  column,
+				Message: fmt.Sprintf("input '%s' is declared more than once", input.Name),
+			})
+		}
+
+		names[input.Name] = true
+
+		path := input.Path
+		if path == "" {
+			path = input.Name
+		}
+
+		if other, found := paths[path]; found && other != input.Name {
+			line, column := locateValue(lines, "name", input.Name)
+			problems = append(problems, TaskConfigProblem{
+				Line:    line,
+				Column:  column,
+				Message: fmt.Sprintf("inputs '%s' and '%s' are both placed at '%s'", other, input.Name, path),
+			})
+		}
+
+		paths[path] = input.Name
+	}
+
+	return problems
+}
+
+// locateKey finds the position of a key in the config by following each
+// segment of its path down through the document. It is approximate, but task
+// configs are small and plainly formatted.
+func locateKey(lines []string, path []string) (int, int) {
+	line, column := 0, 0
+
+	start := 0
+	for _, segment := range path {
+		keyRegex := regexp.MustCompile(`^(\s*(?:-\s+)?)` + regexp.QuoteMeta(segment) + `\s*:`)
+
+		for i := start; i < len(lines); i++ {
+			match := keyRegex.FindStringSubmatch(lines[i])
+			if match != nil {
+				line, column = i+1, len(match[1])+1
+				start = i + 1
+				break
+			}
+		}
+	}
+
+	return line, column
+}
+
+// locateValue finds the last position at which the given key is set to the
+// given value, which for duplicates is the offending declaration.
+func locateValue(lines []string, key string, value string) (int, int) {
+	valueRegex := regexp.MustCompile(`^(\s*(?:-\s+)?)` + regexp.QuoteMeta(key) + `\s*:\s*["']?` + regexp.QuoteMeta(value) + `["']?\s*$`)
+
+	for i := len(lines) - 1; i >= 0; i-- {
+		match := valueRegex.FindStringSubmatch(lines[i])
+		if match != nil {
+			return i + 1, len(match[1]) + 1
+		}
+	}
+
+	return 0, 0
+}
+
+type problemsByPosition []TaskConfigProblem
+
+func (ps problemsByPosition) Len() int          { return len(ps) }
+func (ps problemsByPosition) Swap(i int, j int) { ps[i], ps[j] = ps[j], ps[i] }
+func (ps problemsByPosition) Less(i int, j int) bool {
+	if ps[i].Line == ps[j].Line {
+		return ps[i].Column < ps[j].Column
+	}
+
+	return ps[i].Line < ps[j].Line
+}
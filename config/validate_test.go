@@ -0,0 +1,124 @@
+package config_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/concourse/fly/config"
+)
+
+var _ = Describe("ValidateTaskConfig", func() {
+	It("reports no problems for a valid config", func() {
+		problems := config.ValidateTaskConfig([]byte(`---
+platform: linux
+image: ubuntu
+
+inputs:
+- name: some-input
+
+run:
+  path: ls
+`))
+		Expect(problems).To(BeEmpty())
+	})
+
+	It("reports YAML syntax errors with their line and column", func() {
+		problems := config.ValidateTaskConfig([]byte("---\nplatform: linux\nrun:\n  path: ls\n  \targs: []\n"))
+		Expect(problems).To(HaveLen(1))
+		Expect(problems[0].Line).To(Equal(5))
+		Expect(problems[0].Column).To(Equal(3))
+		Expect(problems[0].Message).To(ContainSubstring("found character that cannot start any token"))
+	})
+
+	It("reports YAML syntax errors with their line", func() {
+		problems := config.ValidateTaskConfig([]byte(`---
+platform: linux
+run:
+  path: ls
+ args: [
+`))
+		Expect(problems).To(HaveLen(1))
+		Expect(problems[0].Line).NotTo(BeZero())
+	})
+
+	It("reports unknown keys with their line and column", func() {
+		problems := config.ValidateTaskConfig([]byte(`---
+platform: linux
+image: ubuntu
+bogus: true
+run:
+  path: ls
+  argz: [-al]
+`))
+		Expect(problems).To(Equal([]config.TaskConfigProblem{
+			{Line: 4, Column: 1, Message: "unknown key 'bogus'"},
+			{Line: 7, Column: 3, Message: "unknown key 'run.argz'"},
+		}))
+	})
+
+	It("does not report arbitrary params as unknown keys", func() {
+		problems := config.ValidateTaskConfig([]byte(`---
+platform: linux
+image: ubuntu
+params:
+  ANYTHING: goes
+run:
+  path: ls
+`))
+		Expect(problems).To(BeEmpty())
+	})
+
+	It("reports values of the wrong type with their line and column", func() {
+		problems := config.ValidateTaskConfig([]byte(`---
+platform: linux
+image: ubuntu
+inputs: some-input
+run:
+  path: ls
+`))
+		Expect(problems).To(HaveLen(1))
+		Expect(problems[0].Line).To(Equal(4))
+		Expect(problems[0].Column).To(Equal(9))
+	})
+
+	It("reports inputs whose names collide", func() {
+		problems := config.ValidateTaskConfig([]byte(`---
+platform: linux
+image: ubuntu
+inputs:
+- name: some-input
+- name: some-input
+run:
+  path: ls
+`))
+		Expect(problems).To(Equal([]config.TaskConfigProblem{
+			{Line: 6, Column: 1, Message: "input 'some-input' is declared more than once"},
+		}))
+	})
+
+	It("reports inputs placed at the same path", func() {
+		problems := config.ValidateTaskConfig([]byte(`---
+platform: linux
+image: ubuntu
+inputs:
+- name: some-input
+- name: some-other-input
+  path: some-input
+run:
+  path: ls
+`))
+		Expect(problems).To(Equal([]config.TaskConfigProblem{
+			{Line: 6, Column: 1, Message: "inputs 'some-input' and 'some-other-input' are both placed at 'some-input'"},
+		}))
+	})
+
+	It("reports a missing run path", func() {
+		problems := config.ValidateTaskConfig([]byte(`---
+platform: linux
+image: ubuntu
+run: {}
+`))
+		Expect(problems).To(HaveLen(1))
+		Expect(problems[0].Message).To(ContainSubstring("missing path to executable to run"))
+	})
+})
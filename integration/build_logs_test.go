@@ -0,0 +1,190 @@
+package integration_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	"github.com/onsi/gomega/gexec"
+	"github.com/onsi/gomega/ghttp"
+	"github.com/vito/go-sse/sse"
+
+	"github.com/concourse/atc"
+	"github.com/concourse/atc/event"
+)
+
+var _ = Describe("Fly CLI", func() {
+	var tmpdir string
+	var atcServer *ghttp.Server
+
+	BeforeEach(func() {
+		var err error
+		tmpdir, err = ioutil.TempDir("", "fly-build-logs")
+		Expect(err).NotTo(HaveOccurred())
+
+		atcServer = ghttp.NewServer()
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(tmpdir)
+		atcServer.Close()
+	})
+
+	Describe("build-logs", func() {
+		getOrigin := event.Origin{Name: "repo", Type: event.OriginTypeGet}
+		unitOrigin := event.Origin{Name: "unit", Type: event.OriginTypeTask}
+
+		buildEvents := []atc.Event{
+			event.Log{Origin: getOrigin, Payload: "cloning repo\n"},
+			event.FinishGet{Origin: getOrigin, ExitStatus: 0},
+			event.Log{Origin: unitOrigin, Payload: "\x1b[32mok\x1b[0m unit tests\n"},
+			event.FinishTask{Origin: unitOrigin, ExitStatus: 1},
+			event.Status{Status: atc.StatusFailed},
+		}
+
+		var buildStatus string
+
+		BeforeEach(func() {
+			buildStatus = "failed"
+		})
+
+		JustBeforeEach(func() {
+			atcServer.RouteToHandler("GET", "/api/v1/builds/3",
+				ghttp.RespondWithJSONEncoded(200, atc.Build{ID: 3, Name: "3", Status: buildStatus}),
+			)
+
+			atcServer.RouteToHandler("GET", "/api/v1/builds/3/events",
+				func(w http.ResponseWriter, r *http.Request) {
+					w.Header().Add("Content-Type", "text/event-stream; charset=utf-8")
+					w.WriteHeader(http.StatusOK)
+
+					for i, e := range buildEvents {
+						payload, err := json.Marshal(event.Message{Event: e})
+						Expect(err).NotTo(HaveOccurred())
+
+						err = sse.Event{
+							ID:   fmt.Sprintf("%d", i),
+							Name: "event",
+							Data: payload,
+						}.Write(w)
+						Expect(err).NotTo(HaveOccurred())
+					}
+
+					err := sse.Event{
+						Name: "end",
+					}.Write(w)
+					Expect(err).NotTo(HaveOccurred())
+				},
+			)
+		})
+
+		It("writes the build's output to stdout as plain text, exiting 0 regardless of its status", func() {
+			flyCmd := exec.Command(flyPath, "-t", atcServer.URL(), "build-logs", "-b", "3")
+
+			sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+
+			<-sess.Exited
+			Expect(sess.ExitCode()).To(Equal(0))
+
+			Expect(sess.Out).To(gbytes.Say("cloning repo"))
+			Expect(sess.Out).To(gbytes.Say("ok unit tests"))
+			Expect(sess.Out).To(gbytes.Say("failed"))
+			Expect(sess.Out.Contents()).NotTo(ContainSubstring("\x1b"))
+		})
+
+		Context("with --format ansi", func() {
+			It("keeps the colors", func() {
+				flyCmd := exec.Command(flyPath, "-t", atcServer.URL(), "build-logs", "-b", "3", "--format", "ansi")
+
+				sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).NotTo(HaveOccurred())
+
+				<-sess.Exited
+				Expect(sess.ExitCode()).To(Equal(0))
+
+				Expect(sess.Out).To(gbytes.Say("\x1b\\[32mok\x1b\\[0m unit tests"))
+				Expect(sess.Out).To(gbytes.Say("\x1b\\[[0-9;]*mfailed"))
+			})
+		})
+
+		Context("with --format json and --output", func() {
+			It("writes each event to the file as a line of JSON", func() {
+				outputPath := filepath.Join(tmpdir, "build.json")
+
+				flyCmd := exec.Command(flyPath, "-t", atcServer.URL(), "build-logs", "-b", "3", "--format", "json", "-o", outputPath)
+
+				sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).NotTo(HaveOccurred())
+
+				<-sess.Exited
+				Expect(sess.ExitCode()).To(Equal(0))
+				Expect(sess.Out.Contents()).To(BeEmpty())
+
+				contents, err := ioutil.ReadFile(outputPath)
+				Expect(err).NotTo(HaveOccurred())
+
+				lines := strings.Split(strings.TrimSpace(string(contents)), "\n")
+				Expect(lines).To(HaveLen(len(buildEvents)))
+
+				var statusMessage struct {
+					Event string       `json:"event"`
+					Data  event.Status `json:"data"`
+				}
+				err = json.Unmarshal([]byte(lines[len(lines)-1]), &statusMessage)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(statusMessage.Event).To(Equal("status"))
+				Expect(statusMessage.Data.Status).To(Equal(atc.StatusFailed))
+			})
+		})
+
+		Context("with --step-dir", func() {
+			It("also writes each step's output to its own file", func() {
+				stepDir := filepath.Join(tmpdir, "steps")
+
+				flyCmd := exec.Command(flyPath, "-t", atcServer.URL(), "build-logs", "-b", "3", "--step-dir", stepDir)
+
+				sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).NotTo(HaveOccurred())
+
+				<-sess.Exited
+				Expect(sess.ExitCode()).To(Equal(0))
+
+				getLog, err := ioutil.ReadFile(filepath.Join(stepDir, "get-repo.log"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(getLog)).To(ContainSubstring("cloning repo\n"))
+
+				unitLog, err := ioutil.ReadFile(filepath.Join(stepDir, "task-unit.log"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(unitLog)).To(ContainSubstring("ok unit tests\n"))
+				Expect(string(unitLog)).NotTo(ContainSubstring("cloning repo"))
+			})
+		})
+
+		Context("when the build has not finished", func() {
+			BeforeEach(func() {
+				buildStatus = "started"
+			})
+
+			It("exits with a client error instead of waiting for it", func() {
+				flyCmd := exec.Command(flyPath, "-t", atcServer.URL(), "build-logs", "-b", "3")
+
+				sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).NotTo(HaveOccurred())
+
+				Eventually(sess.Err).Should(gbytes.Say("build 3 has not finished yet"))
+
+				<-sess.Exited
+				Expect(sess.ExitCode()).To(Equal(10))
+			})
+		})
+	})
+})
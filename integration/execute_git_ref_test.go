@@ -0,0 +1,235 @@
+package integration_test
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	"github.com/onsi/gomega/gexec"
+	"github.com/onsi/gomega/ghttp"
+	"github.com/vito/go-sse/sse"
+
+	"github.com/concourse/atc"
+	"github.com/concourse/atc/event"
+)
+
+var _ = Describe("Fly CLI", func() {
+	var tmpdir string
+	var buildDir string
+	var taskConfigPath string
+
+	var atcServer *ghttp.Server
+	var uploaded chan []string
+	var uploadedBits chan struct{}
+
+	git := func(args ...string) {
+		gitCmd := exec.Command("git", args...)
+		gitCmd.Dir = buildDir
+		gitCmd.Env = append(
+			os.Environ(),
+			"GIT_AUTHOR_NAME=fly",
+			"GIT_AUTHOR_EMAIL=fly@example.com",
+			"GIT_COMMITTER_NAME=fly",
+			"GIT_COMMITTER_EMAIL=fly@example.com",
+		)
+
+		output, err := gitCmd.CombinedOutput()
+		Expect(err).NotTo(HaveOccurred(), string(output))
+	}
+
+	BeforeEach(func() {
+		var err error
+		tmpdir, err = ioutil.TempDir("", "fly-build-dir")
+		Expect(err).NotTo(HaveOccurred())
+
+		buildDir = filepath.Join(tmpdir, "fixture")
+
+		err = os.Mkdir(buildDir, 0755)
+		Expect(err).NotTo(HaveOccurred())
+
+		taskConfigPath = filepath.Join(buildDir, "task.yml")
+
+		err = ioutil.WriteFile(
+			taskConfigPath,
+			[]byte(`---
+platform: some-platform
+
+image: ubuntu
+
+inputs:
+- name: fixture
+
+run:
+  path: find
+  args: [.]
+`),
+			0644,
+		)
+		Expect(err).NotTo(HaveOccurred())
+
+		git("init", "-q")
+		git("add", "task.yml")
+		git("commit", "-q", "-m", "initial")
+
+		err = ioutil.WriteFile(filepath.Join(buildDir, "committed.txt"), []byte("committed"), 0644)
+		Expect(err).NotTo(HaveOccurred())
+
+		git("add", "committed.txt")
+		git("commit", "-q", "-m", "second")
+
+		err = ioutil.WriteFile(filepath.Join(buildDir, "dirty.txt"), []byte("dirty"), 0644)
+		Expect(err).NotTo(HaveOccurred())
+
+		atcServer = ghttp.NewServer()
+		uploaded = make(chan []string, 1)
+		uploadedBits = make(chan struct{})
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(tmpdir)
+	})
+
+	JustBeforeEach(func() {
+		atcServer.RouteToHandler("POST", "/api/v1/pipes",
+			ghttp.RespondWithJSONEncoded(http.StatusCreated, atc.Pipe{
+				ID: "some-pipe-id",
+			}),
+		)
+		atcServer.RouteToHandler("POST", "/api/v1/builds",
+			ghttp.RespondWith(201, `{"id":128}`),
+		)
+		atcServer.RouteToHandler("PUT", "/api/v1/pipes/some-pipe-id",
+			ghttp.CombineHandlers(
+				func(w http.ResponseWriter, req *http.Request) {
+					gr, err := gzip.NewReader(req.Body)
+					Expect(err).NotTo(HaveOccurred())
+
+					tr := tar.NewReader(gr)
+
+					names := []string{}
+					for {
+						hdr, err := tr.Next()
+						if err == io.EOF {
+							break
+						}
+
+						Expect(err).NotTo(HaveOccurred())
+
+						names = append(names, hdr.Name)
+					}
+
+					uploaded <- names
+					close(uploadedBits)
+				},
+				ghttp.RespondWith(200, ""),
+			),
+		)
+		atcServer.RouteToHandler("GET", "/api/v1/builds/128/events",
+			func(w http.ResponseWriter, r *http.Request) {
+				// the build can't finish until its input has been uploaded
+				Eventually(uploadedBits).Should(BeClosed())
+
+				flusher := w.(http.Flusher)
+
+				w.Header().Add("Content-Type", "text/event-stream; charset=utf-8")
+				w.Header().Add("Cache-Control", "no-cache, no-store, must-revalidate")
+				w.Header().Add("Connection", "keep-alive")
+
+				w.WriteHeader(http.StatusOK)
+
+				payload, err := json.Marshal(event.Message{Event: event.Status{Status: atc.StatusSucceeded}})
+				Expect(err).NotTo(HaveOccurred())
+
+				err = sse.Event{
+					ID:   fmt.Sprintf("%d", 0),
+					Name: "event",
+					Data: payload,
+				}.Write(w)
+				Expect(err).NotTo(HaveOccurred())
+
+				err = sse.Event{
+					Name: "end",
+				}.Write(w)
+				Expect(err).NotTo(HaveOccurred())
+
+				flusher.Flush()
+			},
+		)
+	})
+
+	Context("when running with --git-ref", func() {
+		It("uploads the default input from the commit rather than the working tree", func() {
+			flyCmd := exec.Command(flyPath, "-t", atcServer.URL(), "e", "-c", taskConfigPath, "--git-ref", "HEAD")
+			flyCmd.Dir = buildDir
+
+			sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+
+			var names []string
+			Eventually(uploaded).Should(Receive(&names))
+			Expect(names).To(ConsistOf("task.yml", "committed.txt"))
+
+			<-sess.Exited
+			Expect(sess.ExitCode()).To(Equal(0))
+		})
+
+		Context("when inputs are also given", func() {
+			It("exits with a client error", func() {
+				flyCmd := exec.Command(flyPath, "-t", atcServer.URL(), "e", "-c", taskConfigPath, "--git-ref", "HEAD", "-i", "fixture="+buildDir)
+				flyCmd.Dir = buildDir
+
+				sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).NotTo(HaveOccurred())
+
+				Eventually(sess.Err).Should(gbytes.Say("--git-ref only applies to the default input"))
+
+				<-sess.Exited
+				Expect(sess.ExitCode()).To(Equal(10))
+			})
+		})
+	})
+
+	Context("when an input is given as PATH@REF", func() {
+		It("uploads the input from that commit", func() {
+			flyCmd := exec.Command(flyPath, "-t", atcServer.URL(), "e", "-c", taskConfigPath, "-i", "fixture="+buildDir+"@HEAD~1")
+			flyCmd.Dir = buildDir
+
+			sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+
+			var names []string
+			Eventually(uploaded).Should(Receive(&names))
+			Expect(names).To(ConsistOf("task.yml"))
+
+			<-sess.Exited
+			Expect(sess.ExitCode()).To(Equal(0))
+		})
+
+		Context("when the ref does not exist", func() {
+			It("prints an error and exits with a client error without creating the build", func() {
+				flyCmd := exec.Command(flyPath, "-t", atcServer.URL(), "e", "-c", taskConfigPath, "-i", "fixture="+buildDir+"@bogus")
+				flyCmd.Dir = buildDir
+
+				sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).NotTo(HaveOccurred())
+
+				Eventually(sess.Err).Should(gbytes.Say("invalid git ref for input `fixture`: 'bogus' is not a commit"))
+
+				<-sess.Exited
+				Expect(sess.ExitCode()).To(Equal(10))
+
+				Expect(atcServer.ReceivedRequests()).To(BeEmpty())
+			})
+		})
+	})
+})
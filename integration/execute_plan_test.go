@@ -0,0 +1,316 @@
+package integration_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	"github.com/onsi/gomega/gexec"
+	"github.com/onsi/gomega/ghttp"
+	"github.com/vito/go-sse/sse"
+
+	"github.com/concourse/atc"
+	"github.com/concourse/atc/event"
+)
+
+var _ = Describe("Fly CLI", func() {
+	var tmpdir string
+	var sourceDir string
+	var planPath string
+
+	var atcServer *ghttp.Server
+	var streaming chan struct{}
+	var events chan atc.Event
+	var uploading chan struct{}
+
+	var expectedPlan atc.Plan
+
+	BeforeEach(func() {
+		var err error
+		tmpdir, err = ioutil.TempDir("", "fly-plan")
+		Expect(err).NotTo(HaveOccurred())
+
+		sourceDir = filepath.Join(tmpdir, "source")
+
+		err = os.Mkdir(sourceDir, 0755)
+		Expect(err).NotTo(HaveOccurred())
+
+		err = ioutil.WriteFile(
+			filepath.Join(tmpdir, "test.yml"),
+			[]byte(`---
+platform: some-platform
+image: ubuntu
+inputs:
+- name: compiled
+- name: source
+run:
+  path: compiled/test
+`),
+			0644,
+		)
+		Expect(err).NotTo(HaveOccurred())
+
+		planPath = filepath.Join(tmpdir, "plan.yml")
+
+		err = ioutil.WriteFile(
+			planPath,
+			[]byte(`---
+steps:
+- name: compile
+  privileged: true
+  config:
+    platform: some-platform
+    image: ubuntu
+    inputs:
+    - name: source
+    outputs:
+    - name: binary
+    run:
+      path: source/compile
+- name: test
+  file: test.yml
+  params:
+    FOO: bar
+  input_mapping:
+    compiled: binary
+`),
+			0644,
+		)
+		Expect(err).NotTo(HaveOccurred())
+
+		atcServer = ghttp.NewServer()
+
+		streaming = make(chan struct{})
+		events = make(chan atc.Event)
+		uploading = make(chan struct{})
+
+		expectedPlan = atc.Plan{
+			OnSuccess: &atc.OnSuccessPlan{
+				Step: atc.Plan{
+					Aggregate: &atc.AggregatePlan{
+						atc.Plan{
+							Location: &atc.Location{
+								ParallelGroup: 1,
+								ParentID:      0,
+								ID:            2,
+							},
+							Get: &atc.GetPlan{
+								Name: "source",
+								Type: "archive",
+								Source: atc.Source{
+									"uri": atcServer.URL() + "/api/v1/pipes/some-pipe-id",
+								},
+							},
+						},
+					},
+				},
+				Next: atc.Plan{
+					OnSuccess: &atc.OnSuccessPlan{
+						Step: atc.Plan{
+							Location: &atc.Location{
+								ParentID: 0,
+								ID:       3,
+							},
+							Task: &atc.TaskPlan{
+								Name:       "compile",
+								Privileged: true,
+								Config: &atc.TaskConfig{
+									Platform: "some-platform",
+									Image:    "ubuntu",
+									Inputs: []atc.TaskInputConfig{
+										{Name: "source"},
+									},
+									Outputs: []atc.TaskOutputConfig{
+										{Name: "binary"},
+									},
+									Run: atc.TaskRunConfig{
+										Path: "source/compile",
+									},
+								},
+							},
+						},
+						Next: atc.Plan{
+							Location: &atc.Location{
+								ParentID: 0,
+								ID:       4,
+							},
+							Task: &atc.TaskPlan{
+								Name: "test",
+								Config: &atc.TaskConfig{
+									Platform: "some-platform",
+									Image:    "ubuntu",
+									Inputs: []atc.TaskInputConfig{
+										{Name: "compiled"},
+										{Name: "source"},
+									},
+									Params: map[string]string{
+										"FOO": "bar",
+									},
+									Run: atc.TaskRunConfig{
+										Path: "compiled/test",
+									},
+								},
+								InputMapping: map[string]string{
+									"compiled": "binary",
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(tmpdir)
+	})
+
+	JustBeforeEach(func() {
+		atcServer.RouteToHandler("POST", "/api/v1/pipes",
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("POST", "/api/v1/pipes"),
+				ghttp.RespondWithJSONEncoded(http.StatusCreated, atc.Pipe{
+					ID: "some-pipe-id",
+				}),
+			),
+		)
+		atcServer.RouteToHandler("POST", "/api/v1/builds",
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("POST", "/api/v1/builds"),
+				ghttp.VerifyJSONRepresenting(expectedPlan),
+				ghttp.RespondWith(201, `{"id":128}`),
+			),
+		)
+		atcServer.RouteToHandler("GET", "/api/v1/builds/128/events",
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("GET", "/api/v1/builds/128/events"),
+				func(w http.ResponseWriter, r *http.Request) {
+					flusher := w.(http.Flusher)
+
+					w.Header().Add("Content-Type", "text/event-stream; charset=utf-8")
+					w.Header().Add("Cache-Control", "no-cache, no-store, must-revalidate")
+					w.Header().Add("Connection", "keep-alive")
+
+					w.WriteHeader(http.StatusOK)
+
+					flusher.Flush()
+
+					close(streaming)
+
+					id := 0
+
+					for e := range events {
+						payload, err := json.Marshal(event.Message{Event: e})
+						Expect(err).NotTo(HaveOccurred())
+
+						event := sse.Event{
+							ID:   fmt.Sprintf("%d", id),
+							Name: "event",
+							Data: payload,
+						}
+
+						err = event.Write(w)
+						Expect(err).NotTo(HaveOccurred())
+
+						flusher.Flush()
+
+						id++
+					}
+
+					err := sse.Event{
+						Name: "end",
+					}.Write(w)
+					Expect(err).NotTo(HaveOccurred())
+				},
+			),
+		)
+		atcServer.RouteToHandler("PUT", "/api/v1/pipes/some-pipe-id",
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("PUT", "/api/v1/pipes/some-pipe-id"),
+				func(w http.ResponseWriter, req *http.Request) {
+					close(uploading)
+				},
+				ghttp.RespondWith(200, ""),
+			),
+		)
+	})
+
+	Context("when running with --plan", func() {
+		It("runs each step in order, feeding outputs into later inputs", func() {
+			flyCmd := exec.Command(flyPath, "-t", atcServer.URL(), "e", "--plan", planPath, "-i", "source="+sourceDir)
+
+			sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+
+			Eventually(streaming).Should(BeClosed())
+			Eventually(uploading).Should(BeClosed())
+
+			events <- event.Log{
+				Origin:  event.Origin{Name: "compile", Type: event.OriginTypeTask},
+				Payload: "compiling",
+			}
+
+			events <- event.Log{
+				Origin:  event.Origin{Name: "test", Type: event.OriginTypeTask},
+				Payload: "testing",
+			}
+
+			events <- event.Status{Status: atc.StatusSucceeded}
+			close(events)
+
+			Eventually(sess.Out).Should(gbytes.Say("compile"))
+			Eventually(sess.Out).Should(gbytes.Say("compiling"))
+			Eventually(sess.Out).Should(gbytes.Say("test"))
+			Eventually(sess.Out).Should(gbytes.Say("testing"))
+
+			<-sess.Exited
+			Expect(sess.ExitCode()).To(Equal(0))
+		})
+
+		Context("when a step's config is invalid", func() {
+			BeforeEach(func() {
+				err := ioutil.WriteFile(
+					filepath.Join(tmpdir, "test.yml"),
+					[]byte(`---
+run: {}
+`),
+					0644,
+				)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("names the step and exits with a client error", func() {
+				flyCmd := exec.Command(flyPath, "-t", atcServer.URL(), "e", "--plan", planPath, "-i", "source="+sourceDir)
+
+				sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).NotTo(HaveOccurred())
+
+				Eventually(sess.Err).Should(gbytes.Say("invalid config for step 'test'"))
+
+				<-sess.Exited
+				Expect(sess.ExitCode()).To(Equal(10))
+			})
+		})
+
+		Context("when combined with --config", func() {
+			It("exits with a client error", func() {
+				flyCmd := exec.Command(flyPath, "-t", atcServer.URL(), "e", "--plan", planPath, "-c", planPath)
+
+				sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).NotTo(HaveOccurred())
+
+				Eventually(sess.Err).Should(gbytes.Say("only one of --config, --job-task, or --plan can be specified"))
+
+				<-sess.Exited
+				Expect(sess.ExitCode()).To(Equal(10))
+			})
+		})
+	})
+})
@@ -0,0 +1,248 @@
+package integration_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync/atomic"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	"github.com/onsi/gomega/gexec"
+	"github.com/onsi/gomega/ghttp"
+	"github.com/vito/go-sse/sse"
+
+	"github.com/concourse/atc"
+	"github.com/concourse/atc/event"
+)
+
+var _ = Describe("Fly CLI", func() {
+	var tmpdir string
+	var buildDir string
+	var taskConfigPath string
+
+	var atcServer *ghttp.Server
+
+	var statuses map[int]atc.BuildStatus
+	var uploads chan string
+
+	BeforeEach(func() {
+		var err error
+		tmpdir, err = ioutil.TempDir("", "fly-build-dir")
+		Expect(err).NotTo(HaveOccurred())
+
+		buildDir = filepath.Join(tmpdir, "fixture")
+
+		err = os.Mkdir(buildDir, 0755)
+		Expect(err).NotTo(HaveOccurred())
+
+		taskConfigPath = filepath.Join(buildDir, "task.yml")
+
+		err = ioutil.WriteFile(
+			taskConfigPath,
+			[]byte(`---
+platform: some-platform
+
+image: ubuntu
+
+inputs:
+- name: fixture
+
+run:
+  path: find
+  args: [.]
+`),
+			0644,
+		)
+		Expect(err).NotTo(HaveOccurred())
+
+		atcServer = ghttp.NewServer()
+
+		statuses = map[int]atc.BuildStatus{}
+		uploads = make(chan string, 10)
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(tmpdir)
+	})
+
+	JustBeforeEach(func() {
+		var pipes int32
+		var builds int32
+
+		atcServer.RouteToHandler("POST", "/api/v1/pipes",
+			func(w http.ResponseWriter, r *http.Request) {
+				id := atomic.AddInt32(&pipes, 1)
+
+				ghttp.RespondWithJSONEncoded(http.StatusCreated, atc.Pipe{
+					ID: fmt.Sprintf("pipe-%d", id),
+				})(w, r)
+			},
+		)
+
+		atcServer.RouteToHandler("POST", "/api/v1/builds",
+			func(w http.ResponseWriter, r *http.Request) {
+				id := 127 + atomic.AddInt32(&builds, 1)
+
+				ghttp.RespondWith(201, fmt.Sprintf(`{"id":%d}`, id))(w, r)
+			},
+		)
+
+		for i := 1; i <= 3; i++ {
+			pipeID := fmt.Sprintf("pipe-%d", i)
+
+			atcServer.RouteToHandler("PUT", "/api/v1/pipes/"+pipeID,
+				ghttp.CombineHandlers(
+					func(w http.ResponseWriter, r *http.Request) {
+						uploads <- pipeID
+					},
+					ghttp.RespondWith(200, ""),
+				),
+			)
+		}
+
+		for buildID, status := range statuses {
+			status := status
+
+			atcServer.RouteToHandler("GET", fmt.Sprintf("/api/v1/builds/%d/events", buildID),
+				func(w http.ResponseWriter, r *http.Request) {
+					// the build can't finish until its input has been uploaded
+					Eventually(uploads).Should(Receive())
+
+					flusher := w.(http.Flusher)
+
+					w.Header().Add("Content-Type", "text/event-stream; charset=utf-8")
+					w.Header().Add("Cache-Control", "no-cache, no-store, must-revalidate")
+					w.Header().Add("Connection", "keep-alive")
+
+					w.WriteHeader(http.StatusOK)
+
+					payload, err := json.Marshal(event.Message{Event: event.Status{Status: status}})
+					Expect(err).NotTo(HaveOccurred())
+
+					err = sse.Event{
+						ID:   "0",
+						Name: "event",
+						Data: payload,
+					}.Write(w)
+					Expect(err).NotTo(HaveOccurred())
+
+					err = sse.Event{
+						Name: "end",
+					}.Write(w)
+					Expect(err).NotTo(HaveOccurred())
+
+					flusher.Flush()
+				},
+			)
+		}
+	})
+
+	Context("when running with --attempts", func() {
+		Context("when an attempt errors", func() {
+			BeforeEach(func() {
+				statuses[128] = atc.StatusErrored
+				statuses[129] = atc.StatusSucceeded
+			})
+
+			It("retries with a new build and inputs, then summarizes the attempts", func() {
+				flyCmd := exec.Command(flyPath, "-t", atcServer.URL(), "e", "-c", taskConfigPath, "--attempts", "3")
+				flyCmd.Dir = buildDir
+
+				sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).NotTo(HaveOccurred())
+
+				Eventually(sess.Out).Should(gbytes.Say("attempt 1 of 3"))
+				Eventually(sess.Out).Should(gbytes.Say("executing build 128"))
+				Eventually(sess.Out).Should(gbytes.Say("attempt 2 of 3"))
+				Eventually(sess.Out).Should(gbytes.Say("executing build 129"))
+				Eventually(sess.Out).Should(gbytes.Say(`1\s+128\s+errored`))
+				Eventually(sess.Out).Should(gbytes.Say(`2\s+129\s+succeeded`))
+
+				<-sess.Exited
+				Expect(sess.ExitCode()).To(Equal(0))
+				Expect(sess.Out).NotTo(gbytes.Say("attempt 3 of 3"))
+			})
+		})
+
+		Context("when an attempt fails", func() {
+			BeforeEach(func() {
+				statuses[128] = atc.StatusFailed
+			})
+
+			It("does not retry it by default", func() {
+				flyCmd := exec.Command(flyPath, "-t", atcServer.URL(), "e", "-c", taskConfigPath, "--attempts", "3")
+				flyCmd.Dir = buildDir
+
+				sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).NotTo(HaveOccurred())
+
+				Eventually(sess.Out).Should(gbytes.Say(`1\s+128\s+failed`))
+
+				<-sess.Exited
+				Expect(sess.ExitCode()).To(Equal(1))
+				Expect(sess.Out).NotTo(gbytes.Say("attempt 2 of 3"))
+			})
+		})
+
+		Context("with --retry-on any", func() {
+			BeforeEach(func() {
+				statuses[128] = atc.StatusFailed
+				statuses[129] = atc.StatusErrored
+				statuses[130] = atc.StatusFailed
+			})
+
+			It("gives up after the last attempt and exits with its status", func() {
+				flyCmd := exec.Command(flyPath, "-t", atcServer.URL(), "e", "-c", taskConfigPath, "--attempts", "3", "--retry-on", "any")
+				flyCmd.Dir = buildDir
+
+				sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).NotTo(HaveOccurred())
+
+				Eventually(sess.Out).Should(gbytes.Say("attempt 3 of 3"))
+				Eventually(sess.Out).Should(gbytes.Say("executing build 130"))
+				Eventually(sess.Out).Should(gbytes.Say(`1\s+128\s+failed`))
+				Eventually(sess.Out).Should(gbytes.Say(`2\s+129\s+errored`))
+				Eventually(sess.Out).Should(gbytes.Say(`3\s+130\s+failed`))
+
+				<-sess.Exited
+				Expect(sess.ExitCode()).To(Equal(1))
+			})
+		})
+
+		Context("with --detach", func() {
+			It("refuses to run", func() {
+				flyCmd := exec.Command(flyPath, "-t", atcServer.URL(), "e", "-c", taskConfigPath, "--attempts", "3", "--detach")
+				flyCmd.Dir = buildDir
+
+				sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).NotTo(HaveOccurred())
+
+				Eventually(sess.Err).Should(gbytes.Say("a detached build cannot be retried"))
+
+				<-sess.Exited
+				Expect(sess.ExitCode()).To(Equal(10))
+			})
+		})
+
+		Context("with an output streamed to stdout", func() {
+			It("refuses to run", func() {
+				flyCmd := exec.Command(flyPath, "-t", atcServer.URL(), "e", "-c", taskConfigPath, "--attempts", "3", "-o", "some-output=-")
+				flyCmd.Dir = buildDir
+
+				sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).NotTo(HaveOccurred())
+
+				Eventually(sess.Err).Should(gbytes.Say("an output streamed to stdout cannot be retried"))
+
+				<-sess.Exited
+				Expect(sess.ExitCode()).To(Equal(10))
+			})
+		})
+	})
+})
@@ -11,6 +11,7 @@ import (
 	"os/exec"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"syscall"
 	"time"
 
@@ -242,6 +243,7 @@ run:
 
 		Eventually(sess.Out).Should(gbytes.Say("sup"))
 
+		events <- event.Status{Status: atc.StatusSucceeded}
 		close(events)
 
 		<-sess.Exited
@@ -261,7 +263,7 @@ run: {}
 			Expect(err).NotTo(HaveOccurred())
 		})
 
-		It("prints the failure and exits 1", func() {
+		It("prints the failure and exits with a client error", func() {
 			flyCmd := exec.Command(flyPath, "-t", atcServer.URL(), "e", "-c", taskConfigPath)
 			flyCmd.Dir = buildDir
 
@@ -271,7 +273,7 @@ run: {}
 			Eventually(sess.Err).Should(gbytes.Say("missing"))
 
 			<-sess.Exited
-			Expect(sess.ExitCode()).To(Equal(1))
+			Expect(sess.ExitCode()).To(Equal(10))
 		})
 	})
 
@@ -292,6 +294,7 @@ run: {}
 			// sync with after create
 			Eventually(streaming, 5.0).Should(BeClosed())
 
+			events <- event.Status{Status: atc.StatusSucceeded}
 			close(events)
 
 			<-sess.Exited
@@ -310,7 +313,7 @@ run: {}
 			Eventually(sess.Err).Should(gbytes.Say("unknown input `evan`"))
 
 			<-sess.Exited
-			Expect(sess.ExitCode()).To(Equal(1))
+			Expect(sess.ExitCode()).To(Equal(10))
 		})
 
 		Context("when invalid inputs are passed and the single valid input is correctly ommited", func() {
@@ -324,7 +327,7 @@ run: {}
 				Eventually(sess.Err).Should(gbytes.Say("unknown input `evan`"))
 
 				<-sess.Exited
-				Expect(sess.ExitCode()).To(Equal(1))
+				Expect(sess.ExitCode()).To(Equal(10))
 			})
 		})
 	})
@@ -368,7 +371,7 @@ run:
 				Eventually(sess.Err).Should(gbytes.Say("missing required input `fixture`"))
 
 				<-sess.Exited
-				Expect(sess.ExitCode()).To(Equal(1))
+				Expect(sess.ExitCode()).To(Equal(10))
 			})
 
 		})
@@ -384,7 +387,7 @@ run:
 				Eventually(sess.Err).Should(gbytes.Say("missing required input"))
 
 				<-sess.Exited
-				Expect(sess.ExitCode()).To(Equal(1))
+				Expect(sess.ExitCode()).To(Equal(10))
 			})
 
 		})
@@ -408,6 +411,115 @@ run:
 			// sync with after create
 			Eventually(streaming, 5.0).Should(BeClosed())
 
+			events <- event.Status{Status: atc.StatusSucceeded}
+			close(events)
+
+			<-sess.Exited
+			Expect(sess.ExitCode()).To(Equal(0))
+		})
+	})
+
+	Context("when an input is a prebuilt tarball", func() {
+		var archivePath string
+
+		BeforeEach(func() {
+			archivePath = filepath.Join(tmpdir, "fixture.tgz")
+
+			tarCmd := exec.Command("tar", "-czf", archivePath, ".")
+			tarCmd.Dir = buildDir
+
+			err := tarCmd.Run()
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("uploads the archive as-is", func() {
+			flyCmd := exec.Command(flyPath, "-t", atcServer.URL(), "e", "-c", taskConfigPath, "-i", "fixture="+archivePath)
+			flyCmd.Dir = buildDir
+
+			sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+
+			Eventually(streaming, 5).Should(BeClosed())
+			Eventually(uploadingBits).Should(BeClosed())
+
+			events <- event.Status{Status: atc.StatusSucceeded}
+			close(events)
+
+			<-sess.Exited
+			Expect(sess.ExitCode()).To(Equal(0))
+		})
+
+		It("can read the archive from stdin", func() {
+			archive, err := os.Open(archivePath)
+			Expect(err).NotTo(HaveOccurred())
+
+			defer archive.Close()
+
+			flyCmd := exec.Command(flyPath, "-t", atcServer.URL(), "e", "-c", taskConfigPath, "-i", "fixture=-")
+			flyCmd.Dir = buildDir
+			flyCmd.Stdin = archive
+
+			sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+
+			Eventually(streaming, 5).Should(BeClosed())
+			Eventually(uploadingBits).Should(BeClosed())
+
+			events <- event.Status{Status: atc.StatusSucceeded}
+			close(events)
+
+			<-sess.Exited
+			Expect(sess.ExitCode()).To(Equal(0))
+		})
+
+		Context("when the archive is not a gzipped tarball", func() {
+			BeforeEach(func() {
+				err := ioutil.WriteFile(archivePath, []byte("bogus"), 0644)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("prints an error and exits with a client error without creating the build", func() {
+				flyCmd := exec.Command(flyPath, "-t", atcServer.URL(), "e", "-c", taskConfigPath, "-i", "fixture="+archivePath)
+				flyCmd.Dir = buildDir
+
+				sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).NotTo(HaveOccurred())
+
+				Eventually(sess.Err).Should(gbytes.Say("invalid archive for input `fixture`: not a gzipped tarball"))
+
+				<-sess.Exited
+				Expect(sess.ExitCode()).To(Equal(10))
+
+				Expect(atcServer.ReceivedRequests()).To(BeEmpty())
+			})
+		})
+	})
+
+	Context("when running with --tag and --image", func() {
+		BeforeEach(func() {
+			(*expectedPlan.OnSuccess.Step.Aggregate)[0].Get.Tags = atc.Tags{"tag-1", "tag-2"}
+			expectedPlan.OnSuccess.Next.Task.Tags = atc.Tags{"tag-1", "tag-2"}
+			expectedPlan.OnSuccess.Next.Task.Config.Image = "some-other-image"
+		})
+
+		It("tags every step and overrides the task's image", func() {
+			atcServer.AllowUnhandledRequests = true
+
+			flyCmd := exec.Command(
+				flyPath, "-t", atcServer.URL(), "e", "-c", taskConfigPath,
+				"--tag", "tag-1",
+				"--tag", "tag-2",
+				"--image", "some-other-image",
+			)
+			flyCmd.Dir = buildDir
+
+			sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+
+			// sync with after create
+			Eventually(streaming, 5.0).Should(BeClosed())
+
+			events <- event.Status{Status: atc.StatusSucceeded}
 			close(events)
 
 			<-sess.Exited
@@ -416,7 +528,7 @@ run:
 	})
 
 	Context("when running with bogus flags", func() {
-		It("exits 1", func() {
+		It("exits with a client error", func() {
 			flyCmd := exec.Command(flyPath, "-t", atcServer.URL(), "e", "-c", taskConfigPath, "--bogus-flag")
 			flyCmd.Dir = buildDir
 
@@ -426,7 +538,7 @@ run:
 			Eventually(sess.Err).Should(gbytes.Say("unknown flag `bogus-flag'"))
 
 			<-sess.Exited
-			Expect(sess.ExitCode()).To(Equal(1))
+			Expect(sess.ExitCode()).To(Equal(10))
 		})
 	})
 
@@ -452,6 +564,139 @@ run:
 			// sync with after create
 			Eventually(streaming, 5.0).Should(BeClosed())
 
+			events <- event.Status{Status: atc.StatusSucceeded}
+			close(events)
+
+			<-sess.Exited
+			Expect(sess.ExitCode()).To(Equal(0))
+		})
+	})
+
+	Context("when template variables are given", func() {
+		BeforeEach(func() {
+			err := ioutil.WriteFile(
+				taskConfigPath,
+				[]byte(`---
+platform: some-platform
+
+image: {{image}}
+
+inputs:
+- name: fixture
+
+params:
+  FOO: {{foo}}
+  BAZ: buzz
+  X: 1
+
+run:
+  path: find
+  args: [.]
+`),
+				0644,
+			)
+			Expect(err).NotTo(HaveOccurred())
+
+			err = ioutil.WriteFile(
+				filepath.Join(tmpdir, "vars.yml"),
+				[]byte("foo: bar\nimage: not-ubuntu\n"),
+				0644,
+			)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("evaluates them into the task config", func() {
+			atcServer.AllowUnhandledRequests = true
+
+			flyCmd := exec.Command(
+				flyPath, "-t", atcServer.URL(), "e", "-c", taskConfigPath,
+				"-l", filepath.Join(tmpdir, "vars.yml"),
+				"-v", "image=ubuntu",
+			)
+			flyCmd.Dir = buildDir
+
+			sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+
+			// sync with after create
+			Eventually(streaming, 5.0).Should(BeClosed())
+
+			events <- event.Status{Status: atc.StatusSucceeded}
+			close(events)
+
+			<-sess.Exited
+			Expect(sess.ExitCode()).To(Equal(0))
+		})
+
+		Context("when a variable is not bound", func() {
+			It("prints an error and exits with a client error", func() {
+				flyCmd := exec.Command(flyPath, "-t", atcServer.URL(), "e", "-c", taskConfigPath, "-v", "image=ubuntu")
+				flyCmd.Dir = buildDir
+
+				sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).NotTo(HaveOccurred())
+
+				Eventually(sess.Err).Should(gbytes.Say("unbound variable in template: 'foo'"))
+
+				<-sess.Exited
+				Expect(sess.ExitCode()).To(Equal(10))
+			})
+		})
+
+		Context("when no variables are given at all", func() {
+			It("names the unbound variables and exits with a client error", func() {
+				flyCmd := exec.Command(flyPath, "-t", atcServer.URL(), "e", "-c", taskConfigPath)
+				flyCmd.Dir = buildDir
+
+				sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).NotTo(HaveOccurred())
+
+				Eventually(sess.Err).Should(gbytes.Say("unbound variable in template: 'image'"))
+
+				<-sess.Exited
+				Expect(sess.ExitCode()).To(Equal(10))
+			})
+		})
+	})
+
+	Context("when params are given", func() {
+		BeforeEach(func() {
+			expectedPlan.OnSuccess.Next.Task.Config.Params = map[string]string{
+				"FOO": "from-flag",
+				"BAZ": "from-file",
+				"X":   "1",
+				"NEW": "thing",
+			}
+
+			err := ioutil.WriteFile(
+				filepath.Join(tmpdir, "params.yml"),
+				[]byte("FOO: from-file\nBAZ: from-file\n"),
+				0644,
+			)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("overrides the task's params and warns about unknown ones", func() {
+			atcServer.AllowUnhandledRequests = true
+
+			flyCmd := exec.Command(
+				flyPath, "-t", atcServer.URL(), "e", "-c", taskConfigPath,
+				"--params-from", filepath.Join(tmpdir, "params.yml"),
+				"--param", "FOO=from-flag",
+				"--param", "NEW=thing",
+			)
+			flyCmd.Dir = buildDir
+			flyCmd.Env = append(os.Environ(), "FOO=from-env")
+
+			sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+
+			Eventually(sess.Err).Should(gbytes.Say("warning: param 'NEW' is not defined in the task config"))
+
+			// sync with after create
+			Eventually(streaming, 5.0).Should(BeClosed())
+
+			events <- event.Status{Status: atc.StatusSucceeded}
 			close(events)
 
 			<-sess.Exited
@@ -526,6 +771,160 @@ run:
 		}
 	})
 
+	Context("when the build runs past --timeout", func() {
+		var aborted chan struct{}
+
+		JustBeforeEach(func() {
+			aborted = make(chan struct{})
+
+			atcServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("POST", "/api/v1/builds/128/abort"),
+					func(w http.ResponseWriter, r *http.Request) {
+						close(aborted)
+					},
+				),
+			)
+		})
+
+		It("aborts the build, renders it to completion, and exits 4", func() {
+			flyCmd := exec.Command(flyPath, "-t", atcServer.URL(), "e", "-c", taskConfigPath, "--timeout", "100ms")
+			flyCmd.Dir = buildDir
+
+			sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).ToNot(HaveOccurred())
+
+			Eventually(streaming, 5).Should(BeClosed())
+
+			Eventually(aborted, 5.0).Should(BeClosed())
+			Eventually(sess.Err).Should(gbytes.Say("timed out after 100ms; aborting..."))
+
+			events <- event.Log{Payload: "cleaning up"}
+			events <- event.Status{Status: atc.StatusAborted}
+			close(events)
+
+			Eventually(sess.Out).Should(gbytes.Say("cleaning up"))
+
+			<-sess.Exited
+			Expect(sess.ExitCode()).To(Equal(4))
+		})
+
+		Context("when the build finishes in time", func() {
+			It("does not abort it", func() {
+				flyCmd := exec.Command(flyPath, "-t", atcServer.URL(), "e", "-c", taskConfigPath, "--timeout", "1h")
+				flyCmd.Dir = buildDir
+
+				sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).ToNot(HaveOccurred())
+
+				Eventually(streaming, 5).Should(BeClosed())
+
+				events <- event.Status{Status: atc.StatusSucceeded}
+				close(events)
+
+				<-sess.Exited
+				Expect(sess.ExitCode()).To(Equal(0))
+				Expect(aborted).NotTo(BeClosed())
+			})
+		})
+	})
+
+	Context("when an input is over the upload size limit", func() {
+		BeforeEach(func() {
+			err := os.MkdirAll(filepath.Join(buildDir, "node_modules", "some-module"), 0755)
+			Expect(err).NotTo(HaveOccurred())
+
+			err = ioutil.WriteFile(filepath.Join(buildDir, "node_modules", "some-module", "index.js"), make([]byte, 4096), 0644)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("lists the largest paths and exits with a client error without uploading", func() {
+			flyCmd := exec.Command(flyPath, "-t", atcServer.URL(), "e", "-c", taskConfigPath, "--max-upload-size", "1KB")
+			flyCmd.Dir = buildDir
+
+			sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+
+			Eventually(sess.Err).Should(gbytes.Say("input `fixture` is 4.\\dKB, which is over the upload limit of 1.0KB"))
+			Eventually(sess.Err).Should(gbytes.Say("largest paths:"))
+			Eventually(sess.Err).Should(gbytes.Say(`4.0KB  node_modules\n`))
+			Eventually(sess.Err).Should(gbytes.Say(`4.0KB  node_modules/some-module\n`))
+			Eventually(sess.Err).Should(gbytes.Say(`4.0KB  node_modules/some-module/index.js\n`))
+			Eventually(sess.Err).Should(gbytes.Say(`task.yml`))
+			Eventually(sess.Err).Should(gbytes.Say("use --force"))
+
+			<-sess.Exited
+			Expect(sess.ExitCode()).To(Equal(10))
+
+			Expect(atcServer.ReceivedRequests()).To(BeEmpty())
+		})
+
+		Context("when the limit comes from the target in .flyrc", func() {
+			var tmpDir string
+
+			BeforeEach(func() {
+				var err error
+				tmpDir, err = ioutil.TempDir("", "fly-test")
+				Expect(err).NotTo(HaveOccurred())
+
+				if runtime.GOOS == "windows" {
+					os.Setenv("USERPROFILE", tmpDir)
+				} else {
+					os.Setenv("HOME", tmpDir)
+				}
+
+				err = ioutil.WriteFile(
+					filepath.Join(userHomeDir(), ".flyrc"),
+					[]byte(fmt.Sprintf("targets:\n  foo:\n    api: %s\n    max_upload_size: 1KB\n", atcServer.URL())),
+					0600,
+				)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			AfterEach(func() {
+				os.RemoveAll(tmpDir)
+			})
+
+			It("refuses to upload", func() {
+				flyCmd := exec.Command(flyPath, "-t", "foo", "e", "-c", taskConfigPath)
+				flyCmd.Dir = buildDir
+
+				sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).NotTo(HaveOccurred())
+
+				Eventually(sess.Err).Should(gbytes.Say("over the upload limit of 1.0KB"))
+
+				<-sess.Exited
+				Expect(sess.ExitCode()).To(Equal(10))
+			})
+		})
+
+		Context("with --force", func() {
+			BeforeEach(func() {
+				// the upload verifier expects task.yml to come right after ./
+				err := os.RemoveAll(filepath.Join(buildDir, "node_modules"))
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("uploads the input with --force", func() {
+				flyCmd := exec.Command(flyPath, "-t", atcServer.URL(), "e", "-c", taskConfigPath, "--max-upload-size", "10B", "--force")
+				flyCmd.Dir = buildDir
+
+				sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).NotTo(HaveOccurred())
+
+				Eventually(streaming, 5).Should(BeClosed())
+				Eventually(uploadingBits).Should(BeClosed())
+
+				events <- event.Status{Status: atc.StatusSucceeded}
+				close(events)
+
+				<-sess.Exited
+				Expect(sess.ExitCode()).To(Equal(0))
+			})
+		})
+	})
+
 	Context("when the target has an auth token", func() {
 		var tmpDir string
 		var flyrc string
@@ -585,6 +984,151 @@ run:
 		})
 	})
 
+	Context("when running with --detach", func() {
+		It("uploads the inputs, prints the build, and exits without streaming", func() {
+			flyCmd := exec.Command(flyPath, "-t", atcServer.URL(), "e", "-c", taskConfigPath, "--detach")
+			flyCmd.Dir = buildDir
+
+			sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+
+			Eventually(uploadingBits).Should(BeClosed())
+
+			<-sess.Exited
+			Expect(sess.ExitCode()).To(Equal(0))
+
+			Expect(sess.Out).To(gbytes.Say("started build 128"))
+			Expect(sess.Out).To(gbytes.Say(atcServer.URL() + "/builds/128"))
+			Consistently(streaming).ShouldNot(BeClosed())
+		})
+
+		Context("when an input fails to upload", func() {
+			JustBeforeEach(func() {
+				atcServer.RouteToHandler("PUT", "/api/v1/pipes/some-pipe-id",
+					ghttp.RespondWith(http.StatusInternalServerError, ""),
+				)
+			})
+
+			It("exits with a client error instead of reporting the build as started", func() {
+				flyCmd := exec.Command(flyPath, "-t", atcServer.URL(), "e", "-c", taskConfigPath, "--detach")
+				flyCmd.Dir = buildDir
+
+				sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).NotTo(HaveOccurred())
+
+				Eventually(sess.Err).Should(gbytes.Say("uploading bits"))
+
+				<-sess.Exited
+				Expect(sess.ExitCode()).To(Equal(10))
+
+				Expect(sess.Out.Contents()).NotTo(ContainSubstring("started build"))
+			})
+		})
+
+		Context("with --json", func() {
+			It("prints the build as JSON", func() {
+				flyCmd := exec.Command(flyPath, "-t", atcServer.URL(), "e", "-c", taskConfigPath, "--detach", "--json")
+				flyCmd.Dir = buildDir
+
+				sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).NotTo(HaveOccurred())
+
+				<-sess.Exited
+				Expect(sess.ExitCode()).To(Equal(0))
+
+				Expect(sess.Out.Contents()).To(MatchJSON(`{
+					"id": 128,
+					"url": "` + atcServer.URL() + `/builds/128"
+				}`))
+			})
+		})
+
+		Context("with outputs", func() {
+			It("refuses to run", func() {
+				flyCmd := exec.Command(flyPath, "-t", atcServer.URL(), "e", "-c", taskConfigPath, "--detach", "-o", "some-output=.")
+				flyCmd.Dir = buildDir
+
+				sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).NotTo(HaveOccurred())
+
+				Eventually(sess.Err).Should(gbytes.Say("outputs cannot be fetched from a detached build"))
+
+				<-sess.Exited
+				Expect(sess.ExitCode()).To(Equal(10))
+			})
+		})
+	})
+
+	Context("when running with --output-format json", func() {
+		It("writes each event as a line of JSON, and exits with the build's status", func() {
+			flyCmd := exec.Command(flyPath, "-t", atcServer.URL(), "e", "-c", taskConfigPath, "--output-format", "json")
+			flyCmd.Dir = buildDir
+
+			sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+
+			Eventually(streaming, 5).Should(BeClosed())
+			Eventually(sess.Err).Should(gbytes.Say("executing build 128"))
+
+			events <- event.Log{
+				Origin:  event.Origin{Name: "one-off", Type: event.OriginTypeTask},
+				Payload: "sup",
+			}
+			events <- event.Status{Status: atc.StatusFailed}
+			close(events)
+
+			<-sess.Exited
+			Expect(sess.ExitCode()).To(Equal(1))
+
+			lines := strings.Split(strings.TrimSpace(string(sess.Out.Contents())), "\n")
+			Expect(lines).To(HaveLen(2))
+
+			var logMessage struct {
+				Event string    `json:"event"`
+				Data  event.Log `json:"data"`
+			}
+			err = json.Unmarshal([]byte(lines[0]), &logMessage)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(logMessage.Event).To(Equal("log"))
+			Expect(logMessage.Data.Origin.Name).To(Equal("one-off"))
+			Expect(logMessage.Data.Payload).To(Equal("sup"))
+
+			var statusMessage struct {
+				Event string       `json:"event"`
+				Data  event.Status `json:"data"`
+			}
+			err = json.Unmarshal([]byte(lines[1]), &statusMessage)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(statusMessage.Event).To(Equal("status"))
+			Expect(statusMessage.Data.Status).To(Equal(atc.StatusFailed))
+		})
+	})
+
+	Context("when running with --quiet", func() {
+		It("only shows the status the build finished with", func() {
+			flyCmd := exec.Command(flyPath, "-t", atcServer.URL(), "e", "-c", taskConfigPath, "--quiet")
+			flyCmd.Dir = buildDir
+
+			sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+
+			Eventually(streaming, 5).Should(BeClosed())
+
+			events <- event.Log{
+				Origin:  event.Origin{Name: "one-off", Type: event.OriginTypeTask},
+				Payload: "sup",
+			}
+			events <- event.Status{Status: atc.StatusSucceeded}
+			close(events)
+
+			<-sess.Exited
+			Expect(sess.ExitCode()).To(Equal(0))
+
+			Expect(sess.Out).To(gbytes.Say("succeeded"))
+			Expect(sess.Out.Contents()).NotTo(ContainSubstring("sup"))
+		})
+	})
+
 	Context("when the build succeeds", func() {
 		It("exits 0", func() {
 			flyCmd := exec.Command(flyPath, "-t", atcServer.URL(), "e", "-c", taskConfigPath)
@@ -638,4 +1182,22 @@ run:
 			Expect(sess.ExitCode()).To(Equal(2))
 		})
 	})
+
+	Context("when the build is aborted", func() {
+		It("exits 3", func() {
+			flyCmd := exec.Command(flyPath, "-t", atcServer.URL(), "e", "-c", taskConfigPath)
+			flyCmd.Dir = buildDir
+
+			sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).ToNot(HaveOccurred())
+
+			Eventually(streaming, 5).Should(BeClosed())
+
+			events <- event.Status{Status: atc.StatusAborted}
+			close(events)
+
+			<-sess.Exited
+			Expect(sess.ExitCode()).To(Equal(3))
+		})
+	})
 })
@@ -2,6 +2,7 @@ package integration_test
 
 import (
 	"archive/tar"
+	"bytes"
 	"compress/gzip"
 	"encoding/json"
 	"fmt"
@@ -316,6 +317,7 @@ run:
 				// sync with after create
 				Eventually(streaming, 5.0).Should(BeClosed())
 
+				events <- event.Status{Status: atc.StatusSucceeded}
 				close(events)
 
 				<-sess.Exited
@@ -333,8 +335,178 @@ run:
 			})
 		})
 
+		Context("when the output directory has stale files", func() {
+			BeforeEach(func() {
+				err := ioutil.WriteFile(filepath.Join(outputDir, "stale-file"), []byte("stale"), 0644)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			executeWithOutputMode := func(mode string) *gexec.Session {
+				atcServer.AllowUnhandledRequests = true
+
+				flyCmd := exec.Command(flyPath, "-t", atcServer.URL(), "e", "-c", taskConfigPath, "--output", "some-dir="+outputDir, "--output-mode", mode)
+				flyCmd.Dir = buildDir
+
+				sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).NotTo(HaveOccurred())
+
+				return sess
+			}
+
+			outputFileNames := func() []string {
+				outputFiles, err := ioutil.ReadDir(outputDir)
+				Expect(err).NotTo(HaveOccurred())
+
+				names := []string{}
+				for _, f := range outputFiles {
+					names = append(names, f.Name())
+				}
+
+				return names
+			}
+
+			Context("with --output-mode clean", func() {
+				It("replaces the directory with the downloaded output", func() {
+					sess := executeWithOutputMode("clean")
+
+					// sync with after create
+					Eventually(streaming, 5.0).Should(BeClosed())
+
+					events <- event.Status{Status: atc.StatusSucceeded}
+					close(events)
+
+					<-sess.Exited
+					Expect(sess.ExitCode()).To(Equal(0))
+
+					Expect(outputFileNames()).To(Equal([]string{"some-file"}))
+
+					entries, err := ioutil.ReadDir(filepath.Dir(outputDir))
+					Expect(err).NotTo(HaveOccurred())
+
+					for _, entry := range entries {
+						Expect(entry.Name()).NotTo(HavePrefix("." + filepath.Base(outputDir) + "-"))
+					}
+				})
+			})
+
+			Context("with --output-mode merge", func() {
+				It("extracts the output on top of the directory", func() {
+					sess := executeWithOutputMode("merge")
+
+					// sync with after create
+					Eventually(streaming, 5.0).Should(BeClosed())
+
+					events <- event.Status{Status: atc.StatusSucceeded}
+					close(events)
+
+					<-sess.Exited
+					Expect(sess.ExitCode()).To(Equal(0))
+
+					Expect(outputFileNames()).To(ConsistOf("some-file", "stale-file"))
+				})
+			})
+
+			Context("with --output-mode fail", func() {
+				It("refuses to run", func() {
+					sess := executeWithOutputMode("fail")
+
+					Eventually(sess.Err).Should(gbytes.Say("already exists"))
+
+					<-sess.Exited
+					Expect(sess.ExitCode()).To(Equal(10))
+
+					Expect(outputFileNames()).To(Equal([]string{"stale-file"}))
+				})
+			})
+		})
+
+		Context("when the output path is a tarball", func() {
+			It("saves the archive to the file", func() {
+				atcServer.AllowUnhandledRequests = true
+
+				archivePath := filepath.Join(outputDir, "some-dir.tgz")
+
+				flyCmd := exec.Command(flyPath, "-t", atcServer.URL(), "e", "-c", taskConfigPath, "--output", "some-dir="+archivePath)
+				flyCmd.Dir = buildDir
+
+				sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).NotTo(HaveOccurred())
+
+				// sync with after create
+				Eventually(streaming, 5.0).Should(BeClosed())
+
+				events <- event.Status{Status: atc.StatusSucceeded}
+				close(events)
+
+				<-sess.Exited
+				Expect(sess.ExitCode()).To(Equal(0))
+
+				Expect(tarFiles(archivePath)).To(ContainSubstring("some-file"))
+			})
+		})
+
+		Context("when the output path is -", func() {
+			It("streams the archive to stdout and renders the build to stderr", func() {
+				atcServer.AllowUnhandledRequests = true
+
+				flyCmd := exec.Command(flyPath, "-t", atcServer.URL(), "e", "-c", taskConfigPath, "--output", "some-dir=-")
+				flyCmd.Dir = buildDir
+
+				sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).NotTo(HaveOccurred())
+
+				// sync with after create
+				Eventually(streaming, 5.0).Should(BeClosed())
+
+				events <- event.Log{Payload: "sup"}
+				events <- event.Status{Status: atc.StatusSucceeded}
+				close(events)
+
+				<-sess.Exited
+				Expect(sess.ExitCode()).To(Equal(0))
+
+				Expect(sess.Err).To(gbytes.Say("sup"))
+
+				gr, err := gzip.NewReader(bytes.NewReader(sess.Out.Contents()))
+				Expect(err).NotTo(HaveOccurred())
+
+				tr := tar.NewReader(gr)
+
+				hdr, err := tr.Next()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(hdr.Name).To(Equal("some-file"))
+			})
+		})
+
+		Context("when running with --tag", func() {
+			BeforeEach(func() {
+				(*expectedPlan.OnSuccess.Step.Aggregate)[0].Get.Tags = atc.Tags{"some-tag"}
+				expectedPlan.OnSuccess.Next.Ensure.Step.Task.Tags = atc.Tags{"some-tag"}
+				(*expectedPlan.OnSuccess.Next.Ensure.Next.Aggregate)[0].Put.Tags = atc.Tags{"some-tag"}
+			})
+
+			It("tags the gets, the task, and the puts", func() {
+				atcServer.AllowUnhandledRequests = true
+
+				flyCmd := exec.Command(flyPath, "-t", atcServer.URL(), "e", "-c", taskConfigPath, "--output", "some-dir="+outputDir, "--tag", "some-tag")
+				flyCmd.Dir = buildDir
+
+				sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).NotTo(HaveOccurred())
+
+				// sync with after create
+				Eventually(streaming, 5.0).Should(BeClosed())
+
+				events <- event.Status{Status: atc.StatusSucceeded}
+				close(events)
+
+				<-sess.Exited
+				Expect(sess.ExitCode()).To(Equal(0))
+			})
+		})
+
 		Context("when the task does not specify those outputs", func() {
-			It("exits 1", func() {
+			It("exits with a client error", func() {
 				flyCmd := exec.Command(flyPath, "-t", atcServer.URL(), "e", "-c", taskConfigPath, "-o", "wrong-output=wrong-path")
 				flyCmd.Dir = buildDir
 
@@ -344,7 +516,7 @@ run:
 				Eventually(sess.Err).Should(gbytes.Say("error: unknown output 'wrong-output'"))
 
 				<-sess.Exited
-				Expect(sess.ExitCode()).To(Equal(1))
+				Expect(sess.ExitCode()).To(Equal(10))
 			})
 		})
 	})
@@ -83,7 +83,7 @@ var _ = Describe("Fly CLI", func() {
 					Expect(err).NotTo(HaveOccurred())
 
 					<-sess.Exited
-					Expect(sess.ExitCode()).To(Equal(1))
+					Expect(sess.ExitCode()).To(Equal(10))
 
 					Expect(sess.Err).To(gbytes.Say("error: the required flag `" + osFlag("p", "pipeline") + "' was not specified"))
 				})
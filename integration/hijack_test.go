@@ -217,7 +217,7 @@ var _ = Describe("Hijacking", func() {
 			Expect(err).NotTo(HaveOccurred())
 
 			<-sess.Exited
-			Expect(sess.ExitCode()).To(Equal(1))
+			Expect(sess.ExitCode()).To(Equal(10))
 		})
 	})
 
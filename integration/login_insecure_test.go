@@ -200,7 +200,7 @@ var _ = Describe("login -k Command", func() {
 						Expect(err).NotTo(HaveOccurred())
 
 						<-sess.Exited
-						Expect(sess.ExitCode()).To(Equal(1))
+						Expect(sess.ExitCode()).To(Equal(10))
 						Eventually(sess.Err).Should(gbytes.Say("x509: certificate signed by unknown authority"))
 					})
 				})
@@ -225,7 +225,7 @@ var _ = Describe("login -k Command", func() {
 				Expect(err).NotTo(HaveOccurred())
 
 				<-sess.Exited
-				Expect(sess.ExitCode()).To(Equal(1))
+				Expect(sess.ExitCode()).To(Equal(10))
 				Eventually(sess.Err).Should(gbytes.Say("x509: certificate signed by unknown authority"))
 			})
 		})
@@ -308,7 +308,7 @@ var _ = Describe("login -k Command", func() {
 						Expect(err).NotTo(HaveOccurred())
 
 						<-sess.Exited
-						Expect(sess.ExitCode()).To(Equal(1))
+						Expect(sess.ExitCode()).To(Equal(10))
 						Eventually(sess.Err).Should(gbytes.Say("x509: certificate signed by unknown authority"))
 					})
 				})
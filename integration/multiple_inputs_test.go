@@ -283,6 +283,7 @@ run:
 		Eventually(uploadingTwo).Should(BeClosed())
 
 		events <- event.Log{Payload: "sup"}
+		events <- event.Status{Status: atc.StatusSucceeded}
 		close(events)
 
 		Eventually(sess.Out).Should(gbytes.Say("sup"))
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"os"
 	"os/exec"
 	"path/filepath"
 	"time"
@@ -16,6 +17,7 @@ import (
 	"github.com/onsi/gomega/gbytes"
 	"github.com/onsi/gomega/gexec"
 	"github.com/onsi/gomega/ghttp"
+	"github.com/tedsuo/rata"
 	"github.com/vito/go-sse/sse"
 
 	"github.com/concourse/atc"
@@ -277,8 +279,314 @@ run:
 		Eventually(uploading).Should(BeClosed())
 
 		events <- event.Log{Payload: "sup"}
+		events <- event.Status{Status: atc.StatusSucceeded}
 		close(events)
 
 		Eventually(sess.Out).Should(gbytes.Say("sup"))
 	})
+
+	Context("when an input is mapped to a pipeline resource", func() {
+		BeforeEach(func() {
+			(*expectedPlan.OnSuccess.Step.Aggregate)[1].Get = &atc.GetPlan{
+				Name:    "some-other-input",
+				Type:    "git",
+				Source:  atc.Source{"uri": "https://example.com/other"},
+				Version: atc.Version{"ref": "some-ref"},
+			}
+
+			path, err := atc.Routes.CreatePathForRoute(atc.GetConfig, rata.Params{"pipeline_name": "some-pipeline"})
+			Expect(err).NotTo(HaveOccurred())
+
+			atcServer.RouteToHandler("GET", path,
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", path),
+					ghttp.RespondWithJSONEncoded(http.StatusOK, atc.Config{
+						Resources: atc.ResourceConfigs{
+							{
+								Name:   "some-resource",
+								Type:   "git",
+								Source: atc.Source{"uri": "https://example.com"},
+							},
+							{
+								Name:   "some-other-resource",
+								Type:   "git",
+								Source: atc.Source{"uri": "https://example.com/other"},
+							},
+						},
+					}, http.Header{atc.ConfigVersionHeader: {"42"}}),
+				),
+			)
+
+			versionsPath, err := atc.Routes.CreatePathForRoute(atc.ListResourceVersions, rata.Params{
+				"pipeline_name": "some-pipeline",
+				"resource_name": "some-other-resource",
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			atcServer.RouteToHandler("GET", versionsPath,
+				ghttp.RespondWithJSONEncoded(http.StatusOK, []atc.VersionedResource{
+					{Resource: "some-other-resource", Version: atc.Version{"ref": "latest-ref"}},
+					{Resource: "some-other-resource", Version: atc.Version{"ref": "some-ref"}},
+				}),
+			)
+		})
+
+		It("gets the input from the resource at the given version", func() {
+			flyCmd := exec.Command(
+				flyPath, "-t", atcServer.URL(), "e",
+				"--input", fmt.Sprintf("some-input=%s", buildDir),
+				"--input", "some-other-input=@some-pipeline/some-other-resource@ref:some-ref",
+				"--config", filepath.Join(buildDir, "task.yml"),
+			)
+
+			sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+
+			Eventually(streaming).Should(BeClosed())
+			Eventually(uploading).Should(BeClosed())
+
+			events <- event.Log{Payload: "sup"}
+			events <- event.Status{Status: atc.StatusSucceeded}
+			close(events)
+
+			Eventually(sess.Out).Should(gbytes.Say("sup"))
+		})
+
+		Context("when no version is given", func() {
+			BeforeEach(func() {
+				(*expectedPlan.OnSuccess.Step.Aggregate)[1].Get.Version = atc.Version{"ref": "latest-ref"}
+			})
+
+			It("gets the input from the resource at its latest version", func() {
+				flyCmd := exec.Command(
+					flyPath, "-t", atcServer.URL(), "e",
+					"--input", fmt.Sprintf("some-input=%s", buildDir),
+					"--input", "some-other-input=@some-pipeline/some-other-resource",
+					"--config", filepath.Join(buildDir, "task.yml"),
+				)
+
+				sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).NotTo(HaveOccurred())
+
+				Eventually(streaming).Should(BeClosed())
+				Eventually(uploading).Should(BeClosed())
+
+				events <- event.Log{Payload: "sup"}
+				events <- event.Status{Status: atc.StatusSucceeded}
+				close(events)
+
+				Eventually(sess.Out).Should(gbytes.Say("sup"))
+			})
+		})
+
+		Context("when the given version does not exist", func() {
+			It("exits with a client error", func() {
+				flyCmd := exec.Command(
+					flyPath, "-t", atcServer.URL(), "e",
+					"--input", fmt.Sprintf("some-input=%s", buildDir),
+					"--input", "some-other-input=@some-pipeline/some-other-resource@ref:bogus-ref",
+					"--config", filepath.Join(buildDir, "task.yml"),
+				)
+
+				sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).NotTo(HaveOccurred())
+
+				Eventually(sess.Err).Should(gbytes.Say("version ref:bogus-ref of resource 'some-other-resource' not found in pipeline 'some-pipeline'"))
+
+				<-sess.Exited
+				Expect(sess.ExitCode()).To(Equal(10))
+			})
+		})
+
+		Context("when the resource is not in the pipeline", func() {
+			It("prints an error", func() {
+				flyCmd := exec.Command(
+					flyPath, "-t", atcServer.URL(), "e",
+					"--input", fmt.Sprintf("some-input=%s", buildDir),
+					"--input", "some-other-input=@some-pipeline/bogus-resource",
+					"--config", filepath.Join(buildDir, "task.yml"),
+				)
+
+				sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).NotTo(HaveOccurred())
+
+				Eventually(sess.Err).Should(gbytes.Say("resource 'bogus-resource' not found in pipeline 'some-pipeline'"))
+
+				<-sess.Exited
+				Expect(sess.ExitCode()).To(Equal(10))
+			})
+		})
+	})
+
+	Context("when executing a task from a pipeline job", func() {
+		var taskStep atc.PlanConfig
+
+		BeforeEach(func() {
+			taskStep = atc.PlanConfig{
+				Task:       "some-task",
+				Privileged: true,
+				Params:     atc.Params{"FOO": "from-step"},
+				InputMapping: map[string]string{
+					"some-other-input": "some-input",
+				},
+			}
+
+			expectedPlan.OnSuccess.Next.Task.Privileged = true
+			expectedPlan.OnSuccess.Next.Task.Config.Params["FOO"] = "from-step"
+
+			(*expectedPlan.OnSuccess.Step.Aggregate)[1].Get = &atc.GetPlan{
+				Name:    "some-other-input",
+				Type:    "git",
+				Source:  atc.Source{"uri": "https://internet.com"},
+				Params:  atc.Params{"some": "params"},
+				Version: atc.Version{"some": "version"},
+				Tags:    atc.Tags{"tag-1", "tag-2"},
+			}
+		})
+
+		JustBeforeEach(func() {
+			path, err := atc.Routes.CreatePathForRoute(atc.GetConfig, rata.Params{"pipeline_name": "some-pipeline"})
+			Expect(err).NotTo(HaveOccurred())
+
+			atcServer.RouteToHandler("GET", path,
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", path),
+					ghttp.RespondWithJSONEncoded(http.StatusOK, atc.Config{
+						Jobs: atc.JobConfigs{
+							{
+								Name: "some-job",
+								Plan: atc.PlanSequence{
+									{Get: "some-input"},
+									{
+										Do: &atc.PlanSequence{
+											taskStep,
+										},
+									},
+								},
+							},
+						},
+					}, http.Header{atc.ConfigVersionHeader: {"42"}}),
+				),
+			)
+		})
+
+		executeJobTask := func(extraArgs ...string) {
+			flyCmd := exec.Command(
+				flyPath, append([]string{
+					"-t", atcServer.URL(), "e",
+					"--job-task", "some-pipeline/some-job/some-task",
+					"--inputs-from", "some-pipeline/some-job",
+					"--input", fmt.Sprintf("some-input=%s", buildDir),
+				}, extraArgs...)...,
+			)
+
+			sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+
+			Eventually(streaming).Should(BeClosed())
+			Eventually(uploading).Should(BeClosed())
+
+			events <- event.Log{Payload: "sup"}
+			events <- event.Status{Status: atc.StatusSucceeded}
+			close(events)
+
+			Eventually(sess.Out).Should(gbytes.Say("sup"))
+
+			<-sess.Exited
+			Expect(sess.ExitCode()).To(Equal(0))
+		}
+
+		Context("when the task has an inline config", func() {
+			BeforeEach(func() {
+				taskStep.TaskConfig = &atc.TaskConfig{
+					Platform: "some-platform",
+					Image:    "ubuntu",
+					Inputs: []atc.TaskInputConfig{
+						{Name: "some-input"},
+						{Name: "some-other-input"},
+					},
+					Params: map[string]string{
+						"FOO": "bar",
+						"BAZ": "buzz",
+						"X":   "1",
+					},
+					Run: atc.TaskRunConfig{
+						Path: "find",
+						Args: []string{"."},
+					},
+				}
+			})
+
+			It("executes the task with the step's params, privileges, and input mappings", func() {
+				executeJobTask()
+			})
+		})
+
+		Context("when the task's config is a file in an input", func() {
+			BeforeEach(func() {
+				taskStep.TaskConfigPath = "some-input/task.yml"
+			})
+
+			It("reads the config from the local input", func() {
+				executeJobTask()
+			})
+		})
+
+		Context("when the task's config is a file in an input that the task does not take", func() {
+			var ciDir string
+
+			BeforeEach(func() {
+				var err error
+				ciDir, err = ioutil.TempDir("", "fly-ci-dir")
+				Expect(err).NotTo(HaveOccurred())
+
+				err = os.Mkdir(filepath.Join(ciDir, "tasks"), 0755)
+				Expect(err).NotTo(HaveOccurred())
+
+				taskFile, err := ioutil.ReadFile(filepath.Join(buildDir, "task.yml"))
+				Expect(err).NotTo(HaveOccurred())
+
+				err = ioutil.WriteFile(filepath.Join(ciDir, "tasks", "task.yml"), taskFile, 0644)
+				Expect(err).NotTo(HaveOccurred())
+
+				taskStep.TaskConfigPath = "ci/tasks/task.yml"
+			})
+
+			AfterEach(func() {
+				os.RemoveAll(ciDir)
+			})
+
+			It("reads the config from the input without uploading it", func() {
+				executeJobTask("--input", fmt.Sprintf("ci=%s", ciDir))
+			})
+		})
+
+		Context("when the task's config is in an artifact that the step's input_mapping renames", func() {
+			BeforeEach(func() {
+				taskStep.InputMapping["some-input"] = "renamed-input"
+				taskStep.TaskConfigPath = "renamed-input/task.yml"
+			})
+
+			It("reads the config from the task input that the artifact is mapped to", func() {
+				executeJobTask()
+			})
+		})
+
+		Context("when the task is not in the job", func() {
+			It("prints an error", func() {
+				flyCmd := exec.Command(
+					flyPath, "-t", atcServer.URL(), "e",
+					"--job-task", "some-pipeline/some-job/bogus-task",
+				)
+
+				sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).NotTo(HaveOccurred())
+
+				Eventually(sess.Err).Should(gbytes.Say("task 'bogus-task' not found in job 'some-job'"))
+
+				<-sess.Exited
+				Expect(sess.ExitCode()).To(Equal(10))
+			})
+		})
+	})
 })
@@ -250,7 +250,7 @@ var _ = Describe("Fly CLI", func() {
 					Expect(err).NotTo(HaveOccurred())
 
 					<-sess.Exited
-					Expect(sess.ExitCode()).To(Equal(1))
+					Expect(sess.ExitCode()).To(Equal(10))
 
 					Expect(sess.Err).To(gbytes.Say("error: the required flag `" + osFlag("p", "pipeline") + "' was not specified"))
 				})
@@ -349,7 +349,7 @@ var _ = Describe("Fly CLI", func() {
 					no(stdin)
 
 					<-sess.Exited
-					Expect(sess.ExitCode()).To(Equal(1))
+					Expect(sess.ExitCode()).To(Equal(3))
 
 					Expect(atcServer.ReceivedRequests()).To(HaveLen(1))
 				})
@@ -365,7 +365,7 @@ var _ = Describe("Fly CLI", func() {
 					)
 				})
 
-				It("prints the error to stderr and exits 1", func() {
+				It("prints the error to stderr and exits with an auth error", func() {
 					flyCmd := exec.Command(flyPath, "-t", atcServer.URL()+"/", "set-pipeline", "-c", configFile.Name(), "-p", "awesome-pipeline")
 
 					stdin, err := flyCmd.StdinPipe()
@@ -382,7 +382,7 @@ var _ = Describe("Fly CLI", func() {
 					Eventually(sess.Err).Should(gbytes.Say("nope"))
 
 					<-sess.Exited
-					Expect(sess.ExitCode()).To(Equal(1))
+					Expect(sess.ExitCode()).To(Equal(11))
 				})
 			})
 
@@ -441,7 +441,7 @@ var _ = Describe("Fly CLI", func() {
 					})
 				})
 
-				It("prints the error to stderr and exits 1", func() {
+				It("prints the error to stderr and exits with a client error", func() {
 					flyCmd := exec.Command(flyPath, "-t", atcServer.URL()+"/", "set-pipeline", "-c", configFile.Name(), "-p", "awesome-pipeline")
 
 					stdin, err := flyCmd.StdinPipe()
@@ -456,7 +456,7 @@ var _ = Describe("Fly CLI", func() {
 					Eventually(sess.Err).Should(gbytes.Say("failed to update configuration: Put"))
 
 					<-sess.Exited
-					Expect(sess.ExitCode()).To(Equal(1))
+					Expect(sess.ExitCode()).To(Equal(10))
 				})
 			})
 		})
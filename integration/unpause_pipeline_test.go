@@ -76,7 +76,7 @@ var _ = Describe("Fly CLI", func() {
 					Eventually(sess.Err).Should(gbytes.Say(`pipeline 'awesome-pipeline' not found`))
 
 					<-sess.Exited
-					Expect(sess.ExitCode()).To(Equal(1))
+					Expect(sess.ExitCode()).To(Equal(10))
 					Expect(atcServer.ReceivedRequests()).To(HaveLen(1))
 				})
 			})
@@ -91,7 +91,7 @@ var _ = Describe("Fly CLI", func() {
 				Eventually(sess.Err).Should(gbytes.Say(`was not specified`))
 
 				<-sess.Exited
-				Expect(sess.ExitCode()).To(Equal(1))
+				Expect(sess.ExitCode()).To(Equal(10))
 				Expect(atcServer.ReceivedRequests()).To(HaveLen(0))
 			})
 		})
@@ -0,0 +1,104 @@
+package integration_test
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	"github.com/onsi/gomega/gexec"
+)
+
+var _ = Describe("Fly CLI", func() {
+	Describe("validate-task", func() {
+		var tmpdir string
+		var taskConfigPath string
+
+		BeforeEach(func() {
+			var err error
+			tmpdir, err = ioutil.TempDir("", "fly-validate-task")
+			Expect(err).NotTo(HaveOccurred())
+
+			taskConfigPath = filepath.Join(tmpdir, "task.yml")
+		})
+
+		AfterEach(func() {
+			os.RemoveAll(tmpdir)
+		})
+
+		Context("with --help", func() {
+			It("prints the usage and exits 0", func() {
+				flyCmd := exec.Command(flyPath, "validate-task", "--help")
+
+				sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).NotTo(HaveOccurred())
+
+				<-sess.Exited
+				Expect(sess.ExitCode()).To(Equal(0))
+
+				Expect(sess.Out).To(gbytes.Say("Usage:"))
+			})
+		})
+
+		Context("when the config is valid", func() {
+			BeforeEach(func() {
+				err := ioutil.WriteFile(
+					taskConfigPath,
+					[]byte(`---
+platform: some-platform
+image: ubuntu
+run:
+  path: find
+`),
+					0644,
+				)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("exits 0 without contacting a target", func() {
+				flyCmd := exec.Command(flyPath, "-t", "http://127.0.0.1:1", "validate-task", "-c", taskConfigPath)
+
+				sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).NotTo(HaveOccurred())
+
+				<-sess.Exited
+				Expect(sess.ExitCode()).To(Equal(0))
+
+				Expect(sess.Out).To(gbytes.Say("looks good"))
+			})
+		})
+
+		Context("when the config has problems", func() {
+			BeforeEach(func() {
+				err := ioutil.WriteFile(
+					taskConfigPath,
+					[]byte(`---
+platform: some-platform
+image: ubuntu
+bogus: true
+run:
+  path: find
+`),
+					0644,
+				)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("prints each problem with its position and exits 1", func() {
+				flyCmd := exec.Command(flyPath, "-t", "http://127.0.0.1:1", "validate-task", "-c", taskConfigPath)
+
+				sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).NotTo(HaveOccurred())
+
+				<-sess.Exited
+				Expect(sess.ExitCode()).To(Equal(1))
+
+				Expect(sess.Err).To(gbytes.Say(regexp.QuoteMeta(taskConfigPath + ":4:1: unknown key 'bogus'")))
+			})
+		})
+	})
+})
@@ -3,8 +3,13 @@ package integration_test
 import (
 	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"net/http"
+	"os"
 	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -86,12 +91,43 @@ var _ = Describe("Watching", func() {
 
 		Eventually(sess.Out).Should(gbytes.Say("sup"))
 
+		events <- event.Status{Status: atc.StatusSucceeded}
 		close(events)
 
 		<-sess.Exited
 		Expect(sess.ExitCode()).To(Equal(0))
 	}
 
+	finishedEventsHandler := func(buildID int, buildEvents ...atc.Event) http.HandlerFunc {
+		return ghttp.CombineHandlers(
+			ghttp.VerifyRequest("GET", fmt.Sprintf("/api/v1/builds/%d/events", buildID)),
+			func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Add("Content-Type", "text/event-stream; charset=utf-8")
+				w.Header().Add("Cache-Control", "no-cache, no-store, must-revalidate")
+				w.Header().Add("Connection", "keep-alive")
+
+				w.WriteHeader(http.StatusOK)
+
+				for i, e := range buildEvents {
+					payload, err := json.Marshal(event.Message{Event: e})
+					Expect(err).NotTo(HaveOccurred())
+
+					err = sse.Event{
+						ID:   fmt.Sprintf("%d", i),
+						Name: "event",
+						Data: payload,
+					}.Write(w)
+					Expect(err).NotTo(HaveOccurred())
+				}
+
+				err := sse.Event{
+					Name: "end",
+				}.Write(w)
+				Expect(err).NotTo(HaveOccurred())
+			},
+		)
+	}
+
 	Context("with no arguments", func() {
 		BeforeEach(func() {
 			atcServer.AppendHandlers(
@@ -113,6 +149,674 @@ var _ = Describe("Watching", func() {
 		})
 	})
 
+	Context("when the build finishes", func() {
+		BeforeEach(func() {
+			atcServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/api/v1/builds"),
+					ghttp.RespondWithJSONEncoded(200, []atc.Build{
+						{ID: 3, Name: "3", Status: "started"},
+					}),
+				),
+				eventsHandler(),
+			)
+		})
+
+		for status, exitCode := range map[atc.BuildStatus]int{
+			atc.StatusSucceeded: 0,
+			atc.StatusFailed:    1,
+			atc.StatusErrored:   2,
+			atc.StatusAborted:   3,
+		} {
+			status := status
+			exitCode := exitCode
+
+			It(fmt.Sprintf("exits %d when the build has %s", exitCode, status), func() {
+				flyCmd := exec.Command(flyPath, "-t", atcServer.URL(), "watch")
+
+				sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).NotTo(HaveOccurred())
+
+				Eventually(streaming).Should(BeClosed())
+
+				events <- event.Status{Status: status}
+				close(events)
+
+				<-sess.Exited
+				Expect(sess.ExitCode()).To(Equal(exitCode))
+			})
+		}
+	})
+
+	Context("with --output-format json", func() {
+		BeforeEach(func() {
+			atcServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/api/v1/builds"),
+					ghttp.RespondWithJSONEncoded(200, []atc.Build{
+						{ID: 3, Name: "3", Status: "started"},
+					}),
+				),
+				eventsHandler(),
+			)
+		})
+
+		It("writes each event as a line of JSON", func() {
+			flyCmd := exec.Command(flyPath, "-t", atcServer.URL(), "watch", "--output-format", "json")
+
+			sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+
+			Eventually(streaming).Should(BeClosed())
+
+			events <- event.Log{Payload: "sup"}
+
+			Eventually(sess.Out).Should(gbytes.Say(`"event":"log"`))
+
+			events <- event.Status{Status: atc.StatusErrored}
+			close(events)
+
+			Eventually(sess.Out).Should(gbytes.Say(`"event":"status"`))
+
+			<-sess.Exited
+			Expect(sess.ExitCode()).To(Equal(2))
+		})
+	})
+
+	Context("when the ATC rejects the credentials", func() {
+		BeforeEach(func() {
+			atcServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/api/v1/builds"),
+					ghttp.RespondWith(http.StatusUnauthorized, "nope"),
+				),
+			)
+		})
+
+		It("exits 11", func() {
+			flyCmd := exec.Command(flyPath, "-t", atcServer.URL(), "watch")
+
+			sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+
+			<-sess.Exited
+			Expect(sess.ExitCode()).To(Equal(11))
+		})
+	})
+
+	Context("with --timeout", func() {
+		var aborted chan struct{}
+
+		BeforeEach(func() {
+			aborted = make(chan struct{})
+
+			atcServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/api/v1/builds"),
+					ghttp.RespondWithJSONEncoded(200, []atc.Build{
+						{ID: 3, Name: "3", Status: "started"},
+					}),
+				),
+				eventsHandler(),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("POST", "/api/v1/builds/3/abort"),
+					func(w http.ResponseWriter, r *http.Request) {
+						close(aborted)
+					},
+				),
+			)
+		})
+
+		It("aborts the build when it expires and exits 4", func() {
+			flyCmd := exec.Command(flyPath, "-t", atcServer.URL(), "watch", "--timeout", "100ms")
+
+			sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+
+			Eventually(streaming).Should(BeClosed())
+
+			Eventually(aborted, 5.0).Should(BeClosed())
+
+			events <- event.Status{Status: atc.StatusAborted}
+			close(events)
+
+			<-sess.Exited
+			Expect(sess.ExitCode()).To(Equal(4))
+		})
+
+		It("exits with the build's status when it expires after the build has finished", func() {
+			flyCmd := exec.Command(flyPath, "-t", atcServer.URL(), "watch", "--timeout", "100ms")
+
+			sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+
+			Eventually(streaming).Should(BeClosed())
+
+			events <- event.Status{Status: atc.StatusSucceeded}
+
+			Eventually(aborted, 5.0).Should(BeClosed())
+
+			close(events)
+
+			<-sess.Exited
+			Expect(sess.ExitCode()).To(Equal(0))
+		})
+	})
+
+	Context("with --timestamps", func() {
+		BeforeEach(func() {
+			taskOrigin := event.Origin{Name: "unit", Type: event.OriginTypeTask}
+
+			atcServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/api/v1/builds"),
+					ghttp.RespondWithJSONEncoded(200, []atc.Build{
+						{ID: 3, Name: "3", Status: "started"},
+					}),
+				),
+				finishedEventsHandler(3,
+					event.StartTask{Time: 1000, Origin: taskOrigin},
+					event.Log{Time: 1001, Origin: taskOrigin, Payload: "compiling\n"},
+					event.Log{Time: 1065, Origin: taskOrigin, Payload: "testing\npassed\n"},
+					event.FinishTask{Time: 1072, Origin: taskOrigin, ExitStatus: 0},
+					event.Status{Time: 1072, Status: atc.StatusSucceeded},
+				),
+			)
+		})
+
+		It("prefixes each line with the time since the build started and summarizes each step", func() {
+			flyCmd := exec.Command(flyPath, "-t", atcServer.URL(), "watch", "--timestamps=relative")
+
+			sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+
+			<-sess.Exited
+			Expect(sess.ExitCode()).To(Equal(0))
+
+			Expect(sess.Out).To(gbytes.Say("00:00:01 compiling"))
+			Expect(sess.Out).To(gbytes.Say("00:01:05 testing"))
+			Expect(sess.Out).To(gbytes.Say("00:01:05 passed"))
+			Expect(sess.Out).To(gbytes.Say(`unit\s+task\s+succeeded\s+1m12s`))
+		})
+	})
+
+	Context("with --timestamps when replaying a finished build", func() {
+		BeforeEach(func() {
+			getOrigin := event.Origin{Name: "repo", Type: event.OriginTypeGet}
+			taskOrigin := event.Origin{Name: "unit", Type: event.OriginTypeTask}
+
+			atcServer.RouteToHandler("GET", "/api/v1/builds/3",
+				ghttp.RespondWithJSONEncoded(200, atc.Build{ID: 3, Name: "3", Status: "succeeded"}),
+			)
+			atcServer.RouteToHandler("GET", "/api/v1/builds/3/events", finishedEventsHandler(3,
+				event.Log{Time: 1000, Origin: getOrigin, Payload: "cloning\n"},
+				event.Log{Time: 1004, Origin: getOrigin, Payload: "cloned\n"},
+				event.FinishGet{Origin: getOrigin, ExitStatus: 0},
+				event.StartTask{Time: 1010, Origin: taskOrigin},
+				event.FinishTask{Time: 1030, Origin: taskOrigin, ExitStatus: 0},
+				event.Status{Time: 1030, Status: atc.StatusSucceeded},
+			))
+		})
+
+		It("times the events that carry no time by the last one that did", func() {
+			flyCmd := exec.Command(flyPath, "-t", atcServer.URL(), "watch", "-b", "3", "--timestamps=relative")
+
+			sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+
+			<-sess.Exited
+			Expect(sess.ExitCode()).To(Equal(0))
+
+			Expect(sess.Out).To(gbytes.Say("00:00:00 cloning"))
+			Expect(sess.Out).To(gbytes.Say("00:00:04 cloned"))
+			Expect(sess.Out).To(gbytes.Say(`repo\s+get\s+succeeded\s+4s`))
+			Expect(sess.Out).To(gbytes.Say(`unit\s+task\s+succeeded\s+20s`))
+		})
+	})
+
+	Context("when filtering the build's output", func() {
+		BeforeEach(func() {
+			getOrigin := event.Origin{Name: "repo", Type: event.OriginTypeGet}
+			unitOrigin := event.Origin{Name: "unit", Type: event.OriginTypeTask}
+			lintOrigin := event.Origin{Name: "lint", Type: event.OriginTypeTask}
+			putOrigin := event.Origin{Name: "release", Type: event.OriginTypePut}
+
+			atcServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/api/v1/builds"),
+					ghttp.RespondWithJSONEncoded(200, []atc.Build{
+						{ID: 3, Name: "3", Status: "started"},
+					}),
+				),
+				finishedEventsHandler(3,
+					event.Log{Origin: getOrigin, Payload: "cloning repo\n"},
+					event.FinishGet{Origin: getOrigin, ExitStatus: 0},
+					event.Log{Origin: unitOrigin, Payload: "running unit tests\n"},
+					event.Log{Origin: lintOrigin, Payload: "running linter\n"},
+					event.Log{Origin: putOrigin, Payload: "pushing release\n"},
+					event.Error{Origin: putOrigin, Message: "push rejected"},
+					event.Status{Status: atc.StatusFailed},
+				),
+			)
+		})
+
+		Context("with --step", func() {
+			It("only shows the output of the given steps", func() {
+				flyCmd := exec.Command(flyPath, "-t", atcServer.URL(), "watch", "--step", "unit")
+
+				sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).NotTo(HaveOccurred())
+
+				<-sess.Exited
+				Expect(sess.ExitCode()).To(Equal(1))
+
+				Expect(sess.Out).To(gbytes.Say("running unit tests"))
+				Expect(sess.Out).To(gbytes.Say("failed"))
+				Expect(sess.Out.Contents()).NotTo(ContainSubstring("cloning repo"))
+				Expect(sess.Out.Contents()).NotTo(ContainSubstring("running linter"))
+			})
+		})
+
+		Context("with --hide-resources", func() {
+			It("shows a status line for each resource instead of its output", func() {
+				flyCmd := exec.Command(flyPath, "-t", atcServer.URL(), "watch", "--hide-resources")
+
+				sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).NotTo(HaveOccurred())
+
+				<-sess.Exited
+				Expect(sess.ExitCode()).To(Equal(1))
+
+				Expect(sess.Out).To(gbytes.Say("get: repo succeeded"))
+				Expect(sess.Out).To(gbytes.Say("running unit tests"))
+				Expect(sess.Out).To(gbytes.Say("running linter"))
+				Expect(sess.Out).To(gbytes.Say("put: release errored: push rejected"))
+				Expect(sess.Out.Contents()).NotTo(ContainSubstring("cloning repo"))
+				Expect(sess.Out.Contents()).NotTo(ContainSubstring("pushing release"))
+			})
+		})
+
+		Context("with --quiet", func() {
+			It("only shows the status the build finished with", func() {
+				flyCmd := exec.Command(flyPath, "-t", atcServer.URL(), "watch", "--quiet")
+
+				sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).NotTo(HaveOccurred())
+
+				<-sess.Exited
+				Expect(sess.ExitCode()).To(Equal(1))
+
+				Expect(string(sess.Out.Contents())).To(Equal("failed\n"))
+			})
+		})
+	})
+
+	Context("with --log-file", func() {
+		var logDir string
+
+		BeforeEach(func() {
+			var err error
+			logDir, err = ioutil.TempDir("", "fly-log")
+			Expect(err).NotTo(HaveOccurred())
+
+			atcServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/api/v1/builds"),
+					ghttp.RespondWithJSONEncoded(200, []atc.Build{
+						{ID: 3, Name: "3", Status: "started"},
+					}),
+				),
+				finishedEventsHandler(3,
+					event.Log{Payload: "\x1b[1mbold\x1b[0m and \x1b[31mred\x1b[0m\n"},
+					event.Status{Status: atc.StatusSucceeded},
+				),
+			)
+		})
+
+		AfterEach(func() {
+			os.RemoveAll(logDir)
+		})
+
+		It("saves the output to the file without colors, as well as showing it", func() {
+			logPath := filepath.Join(logDir, "build.log")
+
+			flyCmd := exec.Command(flyPath, "-t", atcServer.URL(), "watch", "--log-file", logPath)
+
+			sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+
+			<-sess.Exited
+			Expect(sess.ExitCode()).To(Equal(0))
+
+			Expect(sess.Out).To(gbytes.Say("bold"))
+
+			contents, err := ioutil.ReadFile(logPath)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(contents)).To(ContainSubstring("bold and red\n"))
+			Expect(string(contents)).To(ContainSubstring("succeeded"))
+			Expect(string(contents)).NotTo(ContainSubstring("\x1b"))
+		})
+
+		Context("with --log-format json", func() {
+			It("saves each event to the file as a line of JSON", func() {
+				logPath := filepath.Join(logDir, "build.json")
+
+				flyCmd := exec.Command(flyPath, "-t", atcServer.URL(), "watch", "--log-file", logPath, "--log-format", "json")
+
+				sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).NotTo(HaveOccurred())
+
+				<-sess.Exited
+				Expect(sess.ExitCode()).To(Equal(0))
+
+				contents, err := ioutil.ReadFile(logPath)
+				Expect(err).NotTo(HaveOccurred())
+
+				lines := strings.Split(strings.TrimSpace(string(contents)), "\n")
+				Expect(lines).To(HaveLen(2))
+
+				var statusMessage struct {
+					Event string       `json:"event"`
+					Data  event.Status `json:"data"`
+				}
+				err = json.Unmarshal([]byte(lines[1]), &statusMessage)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(statusMessage.Event).To(Equal("status"))
+				Expect(statusMessage.Data.Status).To(Equal(atc.StatusSucceeded))
+			})
+		})
+	})
+
+	Context("with secret variables", func() {
+		var logDir string
+
+		BeforeEach(func() {
+			var err error
+			logDir, err = ioutil.TempDir("", "fly-log")
+			Expect(err).NotTo(HaveOccurred())
+
+			atcServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/api/v1/builds"),
+					ghttp.RespondWithJSONEncoded(200, []atc.Build{
+						{ID: 3, Name: "3", Status: "started"},
+					}),
+				),
+				finishedEventsHandler(3,
+					event.Log{Payload: "+ login -p hunter22 -k abc123 -u some-user\n"},
+					event.Log{Payload: "+ echo hun"},
+					event.Log{Payload: "ter22 done\n"},
+					event.Log{Payload: "bye hu"},
+					event.Status{Status: atc.StatusSucceeded},
+				),
+			)
+		})
+
+		AfterEach(func() {
+			os.RemoveAll(logDir)
+		})
+
+		It("redacts the values of those named like secrets or given with --secret-var, on the terminal and in the log file", func() {
+			logPath := filepath.Join(logDir, "build.log")
+
+			flyCmd := exec.Command(
+				flyPath, "-t", atcServer.URL(), "watch",
+				"-v", "db_password=hunter22",
+				"-v", "deploy_key=abc123",
+				"-v", "username=some-user",
+				"--secret-var", "deploy_*",
+				"--log-file", logPath,
+			)
+
+			sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+
+			<-sess.Exited
+			Expect(sess.ExitCode()).To(Equal(0))
+
+			Expect(sess.Out).To(gbytes.Say(`login -p \[redacted\] -k \[redacted\] -u some-user`))
+			Expect(sess.Out).To(gbytes.Say(`echo \[redacted\] done`))
+			Expect(sess.Out).To(gbytes.Say("bye hu"))
+			Expect(sess.Out.Contents()).NotTo(ContainSubstring("hun"))
+
+			contents, err := ioutil.ReadFile(logPath)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(contents)).To(ContainSubstring("login -p [redacted] -k [redacted] -u some-user"))
+			Expect(string(contents)).To(ContainSubstring("echo [redacted] done"))
+			Expect(string(contents)).NotTo(ContainSubstring("hun"))
+		})
+	})
+
+	Context("with --on-finish", func() {
+		var hookDir string
+
+		BeforeEach(func() {
+			var err error
+			hookDir, err = ioutil.TempDir("", "fly-hook")
+			Expect(err).NotTo(HaveOccurred())
+
+			atcServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/api/v1/builds"),
+					ghttp.RespondWithJSONEncoded(200, []atc.Build{
+						{ID: 3, Name: "3", Status: "started"},
+					}),
+				),
+				finishedEventsHandler(3,
+					event.Log{Payload: "sup\n"},
+					event.Status{Status: atc.StatusFailed},
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/api/v1/builds/3"),
+					ghttp.RespondWithJSONEncoded(200, atc.Build{
+						ID:        3,
+						Name:      "3",
+						Status:    "failed",
+						StartTime: 1000,
+						EndTime:   1042,
+					}),
+				),
+			)
+		})
+
+		AfterEach(func() {
+			os.RemoveAll(hookDir)
+		})
+
+		It("runs the command with the build's details once it finishes", func() {
+			hookOutput := filepath.Join(hookDir, "finished")
+
+			flyCmd := exec.Command(
+				flyPath, "-t", atcServer.URL(), "watch",
+				"--on-finish", `echo "$FLY_BUILD_ID $FLY_BUILD_STATUS $FLY_BUILD_DURATION $FLY_BUILD_URL" > `+hookOutput,
+			)
+
+			sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+
+			<-sess.Exited
+			Expect(sess.ExitCode()).To(Equal(1))
+
+			contents, err := ioutil.ReadFile(hookOutput)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(contents)).To(Equal(fmt.Sprintf("3 failed 42 %s/builds/3\n", atcServer.URL())))
+		})
+	})
+
+	Context("when the connection to the event stream drops", func() {
+		BeforeEach(func() {
+			atcServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/api/v1/builds"),
+					ghttp.RespondWithJSONEncoded(200, []atc.Build{
+						{ID: 3, Name: "3", Status: "started"},
+					}),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/api/v1/builds/3/events"),
+					func(w http.ResponseWriter, r *http.Request) {
+						flusher := w.(http.Flusher)
+
+						w.Header().Add("Content-Type", "text/event-stream; charset=utf-8")
+						w.WriteHeader(http.StatusOK)
+
+						payload, err := json.Marshal(event.Message{Event: event.Log{Payload: "before the drop\n"}})
+						Expect(err).NotTo(HaveOccurred())
+
+						err = sse.Event{ID: "0", Name: "event", Data: payload}.Write(w)
+						Expect(err).NotTo(HaveOccurred())
+
+						flusher.Flush()
+
+						conn, _, err := w.(http.Hijacker).Hijack()
+						Expect(err).NotTo(HaveOccurred())
+
+						conn.Close()
+					},
+				),
+				finishedEventsHandler(3,
+					event.Log{Payload: "before the drop\n"},
+					event.Log{Payload: "after the drop\n"},
+					event.Status{Status: atc.StatusSucceeded},
+				),
+			)
+		})
+
+		It("reconnects and carries on from the last event it saw", func() {
+			flyCmd := exec.Command(flyPath, "-t", atcServer.URL(), "watch")
+
+			sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+
+			Eventually(sess.Err).Should(gbytes.Say("lost connection to the events of build 3"))
+			Eventually(sess.Err, 5*time.Second).Should(gbytes.Say("reconnected to the events of build 3"))
+
+			Eventually(sess, 5*time.Second).Should(gexec.Exit(0))
+
+			Expect(strings.Count(string(sess.Out.Contents()), "before the drop")).To(Equal(1))
+			Expect(sess.Out.Contents()).To(ContainSubstring("after the drop"))
+		})
+	})
+
+	Context("when the event stream is closed cleanly before the build finishes", func() {
+		BeforeEach(func() {
+			atcServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/api/v1/builds"),
+					ghttp.RespondWithJSONEncoded(200, []atc.Build{
+						{ID: 3, Name: "3", Status: "started"},
+					}),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/api/v1/builds/3/events"),
+					func(w http.ResponseWriter, r *http.Request) {
+						w.Header().Add("Content-Type", "text/event-stream; charset=utf-8")
+						w.WriteHeader(http.StatusOK)
+
+						payload, err := json.Marshal(event.Message{Event: event.Log{Payload: "before the close\n"}})
+						Expect(err).NotTo(HaveOccurred())
+
+						err = sse.Event{ID: "0", Name: "event", Data: payload}.Write(w)
+						Expect(err).NotTo(HaveOccurred())
+					},
+				),
+				finishedEventsHandler(3,
+					event.Log{Payload: "before the close\n"},
+					event.Log{Payload: "after the close\n"},
+					event.Status{Status: atc.StatusSucceeded},
+				),
+			)
+		})
+
+		It("reconnects rather than taking the build to have ended", func() {
+			flyCmd := exec.Command(flyPath, "-t", atcServer.URL(), "watch")
+
+			sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+
+			Eventually(sess.Err).Should(gbytes.Say("lost connection to the events of build 3"))
+			Eventually(sess.Err, 5*time.Second).Should(gbytes.Say("reconnected to the events of build 3"))
+
+			Eventually(sess, 5*time.Second).Should(gexec.Exit(0))
+
+			Expect(strings.Count(string(sess.Out.Contents()), "before the close")).To(Equal(1))
+			Expect(sess.Out.Contents()).To(ContainSubstring("after the close"))
+		})
+	})
+
+	Context("with several builds", func() {
+		BeforeEach(func() {
+			atcServer.RouteToHandler("GET", "/api/v1/builds/3",
+				ghttp.RespondWithJSONEncoded(200, atc.Build{ID: 3, Name: "3", Status: "started"}),
+			)
+			atcServer.RouteToHandler("GET", "/api/v1/builds/4",
+				ghttp.RespondWithJSONEncoded(200, atc.Build{ID: 4, Name: "7", Status: "started", JobName: "other-job", PipelineName: "main"}),
+			)
+			atcServer.RouteToHandler("GET", "/api/v1/builds/3/events", finishedEventsHandler(3,
+				event.Log{Payload: "first build"},
+				event.Status{Status: atc.StatusSucceeded},
+			))
+			atcServer.RouteToHandler("GET", "/api/v1/builds/4/events", finishedEventsHandler(4,
+				event.Log{Payload: "second build"},
+				event.Status{Status: atc.StatusErrored},
+			))
+		})
+
+		It("prefixes each build's output with its tag, summarizes them, and exits with the worst status", func() {
+			flyCmd := exec.Command(flyPath, "-t", atcServer.URL(), "watch", "-b", "3", "-b", "4")
+
+			sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+
+			<-sess.Exited
+			Expect(sess.ExitCode()).To(Equal(2))
+
+			Expect(string(sess.Out.Contents())).To(MatchRegexp(`one-off#3\s+\| first build`))
+			Expect(string(sess.Out.Contents())).To(MatchRegexp(`main/other-job#7 \| second build`))
+
+			Expect(sess.Out).To(gbytes.Say(`one-off#3\s+succeeded`))
+			Expect(sess.Out).To(gbytes.Say(`main/other-job#7\s+errored`))
+		})
+
+		Context("when one job is given for all of the builds", func() {
+			BeforeEach(func() {
+				atcServer.RouteToHandler("GET", "/api/v1/pipelines/main/jobs/other-job/builds/6",
+					ghttp.RespondWithJSONEncoded(200, atc.Build{ID: 3, Name: "6", Status: "started", JobName: "other-job", PipelineName: "main"}),
+				)
+				atcServer.RouteToHandler("GET", "/api/v1/pipelines/main/jobs/other-job/builds/7",
+					ghttp.RespondWithJSONEncoded(200, atc.Build{ID: 4, Name: "7", Status: "started", JobName: "other-job", PipelineName: "main"}),
+				)
+			})
+
+			It("watches those builds of the job", func() {
+				flyCmd := exec.Command(flyPath, "-t", atcServer.URL(), "watch", "-b", "6", "-j", "main/other-job", "-b", "7")
+
+				sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).NotTo(HaveOccurred())
+
+				<-sess.Exited
+				Expect(sess.ExitCode()).To(Equal(2))
+
+				Expect(string(sess.Out.Contents())).To(MatchRegexp(`main/other-job#6 \| first build`))
+				Expect(string(sess.Out.Contents())).To(MatchRegexp(`main/other-job#7 \| second build`))
+			})
+		})
+
+		Context("when the jobs and builds cannot be paired up", func() {
+			It("exits with a client error", func() {
+				flyCmd := exec.Command(flyPath, "-t", atcServer.URL(), "watch", "-j", "main/a", "-j", "main/b", "-b", "1", "-b", "2", "-b", "3")
+
+				sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).NotTo(HaveOccurred())
+
+				Eventually(sess.Err).Should(gbytes.Say("cannot pair 3 builds with 2 jobs"))
+
+				<-sess.Exited
+				Expect(sess.ExitCode()).To(Equal(10))
+			})
+		})
+	})
+
 	Context("with a specific job and pipeline", func() {
 		Context("when the job has no builds", func() {
 			BeforeEach(func() {
@@ -132,7 +836,7 @@ var _ = Describe("Watching", func() {
 
 				Eventually(sess.Err).Should(gbytes.Say("job has no builds"))
 				<-sess.Exited
-				Expect(sess.ExitCode()).To(Equal(1))
+				Expect(sess.ExitCode()).To(Equal(10))
 			})
 		})
 
@@ -192,6 +896,83 @@ var _ = Describe("Watching", func() {
 			})
 		})
 
+		Context("with --follow", func() {
+			BeforeEach(func() {
+				atcServer.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("GET", "/api/v1/pipelines/main/jobs/some-job"),
+						ghttp.RespondWithJSONEncoded(200, atc.Job{
+							NextBuild: &atc.Build{ID: 3, Name: "3", Status: "started", JobName: "some-job"},
+						}),
+					),
+					eventsHandler(),
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("GET", "/api/v1/pipelines/main/jobs/some-job/builds", "limit=100&until=3"),
+						ghttp.RespondWithJSONEncoded(200, []atc.Build{
+							{ID: 5, Name: "5", Status: "pending", JobName: "some-job"},
+							{ID: 4, Name: "4", Status: "started", JobName: "some-job"},
+						}),
+					),
+					finishedEventsHandler(4,
+						event.Log{Payload: "second build"},
+						event.Status{Status: atc.StatusFailed},
+					),
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("GET", "/api/v1/pipelines/main/jobs/some-job/builds", "limit=100&until=4"),
+						ghttp.RespondWithJSONEncoded(200, []atc.Build{
+							{ID: 5, Name: "5", Status: "started", JobName: "some-job"},
+						}),
+					),
+					finishedEventsHandler(5,
+						event.Log{Payload: "third build"},
+						event.Status{Status: atc.StatusSucceeded},
+					),
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("GET", "/api/v1/pipelines/main/jobs/some-job/builds", "limit=100&until=5"),
+						ghttp.RespondWithJSONEncoded(200, []atc.Build{}),
+					),
+				)
+			})
+
+			It("watches each build of the job in turn until interrupted", func() {
+				flyCmd := exec.Command(flyPath, "-t", atcServer.URL(), "watch", "--job", "main/some-job", "--follow")
+
+				sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).NotTo(HaveOccurred())
+
+				Eventually(streaming).Should(BeClosed())
+
+				events <- event.Log{Payload: "first build"}
+				events <- event.Status{Status: atc.StatusSucceeded}
+				close(events)
+
+				Eventually(sess.Out).Should(gbytes.Say("first build"))
+				Eventually(sess.Out).Should(gbytes.Say("--- some-job #3 succeeded ---"))
+				Eventually(sess.Out).Should(gbytes.Say("second build"))
+				Eventually(sess.Out).Should(gbytes.Say("--- some-job #4 failed ---"))
+				Eventually(sess.Out).Should(gbytes.Say("third build"))
+				Eventually(sess.Out).Should(gbytes.Say("--- some-job #5 succeeded ---"))
+				Eventually(sess.Out).Should(gbytes.Say("waiting for the next build..."))
+
+				sess.Interrupt()
+				Eventually(sess).Should(gexec.Exit())
+			})
+
+			Context("without a job", func() {
+				It("exits with a client error", func() {
+					flyCmd := exec.Command(flyPath, "-t", atcServer.URL(), "watch", "--follow")
+
+					sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+					Expect(err).NotTo(HaveOccurred())
+
+					Eventually(sess.Err).Should(gbytes.Say("--follow can only be used with --job"))
+
+					<-sess.Exited
+					Expect(sess.ExitCode()).To(Equal(10))
+				})
+			})
+		})
+
 		Context("with a specific build of the job", func() {
 			BeforeEach(func() {
 				atcServer.AppendHandlers(
@@ -12,8 +12,13 @@ func main() {
 	parser := flags.NewParser(&commands.Fly, flags.HelpFlag|flags.PassDoubleDash)
 
 	_, err := parser.Parse()
+	if flagsErr, ok := err.(*flags.Error); ok && flagsErr.Type == flags.ErrHelp {
+		fmt.Println(err)
+		os.Exit(commands.ExitSucceeded)
+	}
+
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "error: %s\n", err)
-		os.Exit(1)
+		os.Exit(commands.ExitCodeForError(err))
 	}
 }
@@ -19,9 +19,10 @@ import (
 )
 
 type TargetProps struct {
-	API      string       `yaml:"api"`
-	Insecure bool         `yaml:"insecure,omitempty"`
-	Token    *TargetToken `yaml:"token,omitempty"`
+	API           string       `yaml:"api"`
+	Insecure      bool         `yaml:"insecure,omitempty"`
+	Token         *TargetToken `yaml:"token,omitempty"`
+	MaxUploadSize string       `yaml:"max_upload_size,omitempty"`
 }
 
 type TargetToken struct {